@@ -0,0 +1,50 @@
+package fxzerolog
+
+import (
+	"github.com/rs/zerolog"
+)
+
+// WithAggregatedTypes logs a single line for each provided, replaced or
+// decorated event, with the names of all its types in a types array, instead
+// of a line per type with a type field.
+func WithAggregatedTypes() Option {
+	return func(l *ZerologLogger) {
+		l.aggregateTypes = true
+	}
+}
+
+// outputTypes returns the type fields of the lines logged for the output
+// types of a provided, replaced or decorated event, one per line.
+func (l *ZerologLogger) outputTypes(names []string) []zerolog.LogObjectMarshaler {
+	if len(names) == 0 {
+		return nil
+	}
+
+	f := l.fieldNames()
+	if l.aggregateTypes {
+		return []zerolog.LogObjectMarshaler{typesField{key: f.Types, names: names, array: true}}
+	}
+
+	fields := make([]zerolog.LogObjectMarshaler, len(names))
+	for i := range names {
+		fields[i] = typesField{key: f.Type, names: names[i : i+1]}
+	}
+
+	return fields
+}
+
+// typesField encodes output types, as an array or as their first name.
+type typesField struct {
+	key   string
+	names []string
+	array bool
+}
+
+func (f typesField) MarshalZerologObject(e *zerolog.Event) {
+	if f.array {
+		e.Strs(f.key, f.names)
+		return
+	}
+
+	e.Str(f.key, f.names[0])
+}
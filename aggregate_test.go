@@ -0,0 +1,68 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestWithAggregatedTypes(t *testing.T) {
+	events := []fxevent.Event{
+		&fxevent.Provided{
+			ConstructorName: "main.new",
+			OutputTypeNames: []string{"*main.A", "*main.B"},
+			ModuleName:      "myModule",
+			Private:         true,
+		},
+		&fxevent.Replaced{OutputTypeNames: []string{"*main.A", "*main.B"}},
+		&fxevent.Decorated{DecoratorName: "main.decorate", OutputTypeNames: []string{"*main.A"}},
+		&fxevent.Provided{ConstructorName: "main.new", Err: errors.New("some error")},
+	}
+
+	t.Run("aggregated", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithAggregatedTypes())
+		for _, event := range events {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 4)
+		assert.Equal(t, "provided", logs[0].Message())
+		assert.Equal(t, map[string]any{
+			"constructor": "main.new",
+			"stacktrace":  []any{},
+			"moduletrace": []any{},
+			"module":      "myModule",
+			"types":       []any{"*main.A", "*main.B"},
+			"private":     true,
+		}, logs[0].Fields())
+		assert.Equal(t, "replaced", logs[1].Message())
+		assert.Equal(t, []any{"*main.A", "*main.B"}, logs[1].Fields()["types"])
+		assert.Equal(t, "decorated", logs[2].Message())
+		assert.Equal(t, []any{"*main.A"}, logs[2].Fields()["types"])
+		assert.Equal(t, "error encountered while applying options", logs[3].Message())
+		for _, log := range logs {
+			assert.NotContains(t, log.Fields(), "type")
+		}
+	})
+
+	t.Run("per type", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core)
+		for _, event := range events {
+			l.LogEvent(event)
+		}
+
+		var types []any
+		for _, log := range observedLogs.TakeAll() {
+			assert.NotContains(t, log.Fields(), "types")
+			types = append(types, log.Fields()["type"])
+		}
+		assert.Equal(t, []any{"*main.A", "*main.B", "*main.A", "*main.B", "*main.A", nil}, types)
+	})
+}
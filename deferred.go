@@ -0,0 +1,265 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// DeferredStartup configures WithDeferredStartup.
+type DeferredStartup struct {
+	// MaxEvents caps the number of events held, the oldest being dropped
+	// first. Zero means no cap.
+	MaxEvents int
+	// MaxBytes caps the memory held by the events, the oldest being dropped
+	// first. Zero means no cap. The memory is estimated from the events and
+	// the strings they hold, such as stack traces, module traces and names.
+	MaxBytes int
+	// Summarize logs a "deferred events discarded" line with the number of
+	// events discarded, and dropped because of MaxEvents or MaxBytes, on a
+	// successful start.
+	Summarize bool
+}
+
+// WithDeferredStartup holds the events logged until the application has
+// started, instead of logging them. They are replayed on the first failure,
+// i.e. the first event with an error, such as a failed constructor, OnStart
+// hook or invoke, or fxevent.RollingBack, before the line of the failure and
+// at its level, with replayed=true. They are discarded once the application
+// has started. Events are logged as they come after the first failure.
+//
+// Slow events escalated by WithSlowThresholds are logged as they come.
+// Events dropped by WithFilter are neither held nor replayed. The keys are
+// set by FieldNames.
+func WithDeferredStartup(config DeferredStartup) Option {
+	return func(l *ZerologLogger) {
+		l.deferred = &deferredStartup{config: config}
+	}
+}
+
+// deferredStartup holds the events deferred until the application starts.
+type deferredStartup struct {
+	config DeferredStartup
+
+	events  deferredRing
+	dropped int
+	done    bool
+}
+
+// deferredEvent is an event held with what was derived when it was received.
+type deferredEvent struct {
+	event fxevent.Event
+	info  eventInfo
+	size  int
+}
+
+func (d *deferredStartup) validate() error {
+	if d.config.MaxEvents < 0 {
+		return fmt.Errorf("fxzerolog: invalid max deferred events %d", d.config.MaxEvents)
+	}
+
+	if d.config.MaxBytes < 0 {
+		return fmt.Errorf("fxzerolog: invalid max deferred bytes %d", d.config.MaxBytes)
+	}
+
+	return nil
+}
+
+// observe holds event if hold is set, returning whether it was held, and the
+// held events if event ends the startup: a failure or fxevent.Started.
+func (d *deferredStartup) observe(event fxevent.Event, info eventInfo, hold bool) (bool, *deferredFlush) {
+	if d.done {
+		return false, nil
+	}
+
+	// The first failure ends the startup, so that the events held are
+	// replayed before its line.
+	if eventErr(event) != nil {
+		return false, d.end(true)
+	}
+
+	switch event.(type) {
+	case *fxevent.RollingBack:
+		return false, d.end(true)
+	case *fxevent.Started:
+		return false, d.end(false)
+	}
+
+	if !hold {
+		return false, nil
+	}
+
+	e := deferredEvent{event: event, info: info, size: eventSize(event)}
+	if d.config.MaxBytes > 0 && e.size > d.config.MaxBytes {
+		d.dropped++
+		return true, nil
+	}
+
+	for d.full(e.size) {
+		d.events.pop()
+		d.dropped++
+	}
+	d.events.push(e)
+	return true, nil
+}
+
+// full reports whether an event of size must be dropped to hold another.
+func (d *deferredStartup) full(size int) bool {
+	if d.config.MaxEvents > 0 && d.events.len() >= d.config.MaxEvents {
+		return true
+	}
+
+	return d.config.MaxBytes > 0 && d.events.size+size > d.config.MaxBytes
+}
+
+// end stops holding events, returning those held.
+func (d *deferredStartup) end(replay bool) *deferredFlush {
+	flush := &deferredFlush{events: d.events.all(), dropped: d.dropped, replay: replay}
+	d.events = deferredRing{}
+	d.dropped = 0
+	d.done = true
+	return flush
+}
+
+// deferredFlush are the events held until the end of the startup.
+type deferredFlush struct {
+	events  []deferredEvent
+	dropped int
+	// replay reports whether the startup failed.
+	replay bool
+}
+
+// flushDeferred replays or discards the events held until event, which ended
+// the startup.
+func (l *ZerologLogger) flushDeferred(event fxevent.Event, info eventInfo, flush *deferredFlush) {
+	if flush.replay {
+		level := l.errorLevelFor(event)
+		for _, e := range flush.events {
+			replayed := e.info
+			replayed.replay = &level
+			l.encode(e.event, replayed)
+		}
+		return
+	}
+
+	if l.deferred.config.Summarize {
+		f := l.fieldNames()
+		l.newEvent(event, info, l.levelFor(event), false).
+			Int(f.Discarded, len(flush.events)).
+			Int(f.Dropped, flush.dropped).
+			Msg("deferred events discarded")
+	}
+}
+
+// deferredRing holds the deferred events in order, growing as needed.
+type deferredRing struct {
+	events []deferredEvent
+	first  int
+	n      int
+	// size is the sum of the sizes of the events held.
+	size int
+}
+
+func (r *deferredRing) len() int {
+	return r.n
+}
+
+func (r *deferredRing) push(e deferredEvent) {
+	if r.n == len(r.events) {
+		events := make([]deferredEvent, max(2*len(r.events), 16))
+		copy(events, r.all())
+		r.events = events
+		r.first = 0
+	}
+
+	r.events[(r.first+r.n)%len(r.events)] = e
+	r.n++
+	r.size += e.size
+}
+
+// pop drops the oldest event.
+func (r *deferredRing) pop() {
+	r.size -= r.events[r.first].size
+	r.events[r.first] = deferredEvent{}
+	r.first = (r.first + 1) % len(r.events)
+	r.n--
+}
+
+// all returns the events held, oldest first.
+func (r *deferredRing) all() []deferredEvent {
+	events := make([]deferredEvent, 0, r.n)
+	if r.first+r.n <= len(r.events) {
+		return append(events, r.events[r.first:r.first+r.n]...)
+	}
+
+	events = append(events, r.events[r.first:]...)
+	return append(events, r.events[:r.first+r.n-len(r.events)]...)
+}
+
+// eventSize estimates the memory held by event: its fields, and the strings
+// and slices they reference.
+func eventSize(event fxevent.Event) int {
+	v := reflect.ValueOf(event)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	return int(v.Type().Size()) + referencedSize(v)
+}
+
+// referencedSize returns the size of the strings and slices referenced by v.
+func referencedSize(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len()
+	case reflect.Slice:
+		size := v.Len() * int(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += referencedSize(v.Index(i))
+		}
+		return size
+	case reflect.Struct:
+		var size int
+		for i := 0; i < v.NumField(); i++ {
+			size += referencedSize(v.Field(i))
+		}
+		return size
+	}
+
+	return 0
+}
+
+// eventErr returns the error reported by event, if any.
+func eventErr(event fxevent.Event) error {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuted:
+		return e.Err
+	case *fxevent.OnStopExecuted:
+		return e.Err
+	case *fxevent.Supplied:
+		return e.Err
+	case *fxevent.Provided:
+		return e.Err
+	case *fxevent.Replaced:
+		return e.Err
+	case *fxevent.Decorated:
+		return e.Err
+	case *fxevent.Run:
+		return e.Err
+	case *fxevent.Invoked:
+		return e.Err
+	case *fxevent.Stopped:
+		return e.Err
+	case *fxevent.RollingBack:
+		return e.StartErr
+	case *fxevent.RolledBack:
+		return e.Err
+	case *fxevent.Started:
+		return e.Err
+	case *fxevent.LoggerInitialized:
+		return e.Err
+	}
+
+	return nil
+}
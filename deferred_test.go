@@ -0,0 +1,279 @@
+package fxzerolog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestWithDeferredStartup(t *testing.T) {
+	someError := errors.New("some error")
+	startup := []fxevent.Event{
+		&fxevent.Provided{ConstructorName: "main.new", OutputTypeNames: []string{"*main.A"}},
+		&fxevent.Invoking{FunctionName: "main.run"},
+		&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.new"},
+	}
+
+	type line struct {
+		level    string
+		message  string
+		replayed any
+	}
+	lines := func(logs []zerologObservableEntry) []line {
+		var lines []line
+		for _, log := range logs {
+			lines = append(lines, line{log.Level(), log.Message(), log.Fields()["replayed"]})
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name string
+		give DeferredStartup
+		end  []fxevent.Event
+		want []line
+	}{
+		{
+			name: "rolling back",
+			end:  []fxevent.Event{&fxevent.RollingBack{StartErr: someError}},
+			want: []line{
+				{"error", "provided", true},
+				{"error", "invoking", true},
+				{"error", "OnStart hook executing", true},
+				{"error", "start failed, rolling back", nil},
+			},
+		},
+		{
+			name: "failed hook",
+			end: []fxevent.Event{
+				&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.new", Err: someError},
+				&fxevent.RollingBack{StartErr: someError},
+			},
+			want: []line{
+				{"error", "provided", true},
+				{"error", "invoking", true},
+				{"error", "OnStart hook executing", true},
+				{"error", "OnStart hook failed", nil},
+				{"error", "start failed, rolling back", nil},
+			},
+		},
+		{
+			name: "failed constructor",
+			end: []fxevent.Event{
+				&fxevent.Run{Name: "main.new", Kind: "provide", Err: someError},
+				&fxevent.Invoked{FunctionName: "main.run", Err: someError},
+			},
+			want: []line{
+				{"error", "provided", true},
+				{"error", "invoking", true},
+				{"error", "OnStart hook executing", true},
+				{"error", "error returned", nil},
+				{"error", "invoke failed", nil},
+			},
+		},
+		{
+			name: "failed invoke",
+			give: DeferredStartup{MaxEvents: 2},
+			end:  []fxevent.Event{&fxevent.Invoked{FunctionName: "main.run", Err: someError}},
+			want: []line{
+				{"error", "invoking", true},
+				{"error", "OnStart hook executing", true},
+				{"error", "invoke failed", nil},
+			},
+		},
+		{
+			name: "failed start",
+			end:  []fxevent.Event{&fxevent.Started{Err: someError}},
+			want: []line{
+				{"error", "provided", true},
+				{"error", "invoking", true},
+				{"error", "OnStart hook executing", true},
+				{"error", "start failed", nil},
+			},
+		},
+		{
+			name: "started",
+			end:  []fxevent.Event{&fxevent.Invoked{FunctionName: "main.run"}, &fxevent.Started{}},
+			want: []line{
+				{"debug", "started", nil},
+			},
+		},
+		{
+			name: "summarized",
+			give: DeferredStartup{MaxEvents: 2, Summarize: true},
+			end:  []fxevent.Event{&fxevent.Invoked{FunctionName: "main.run"}, &fxevent.Started{}},
+			want: []line{
+				{"debug", "deferred events discarded", nil},
+				{"debug", "started", nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+			l := New(core, WithDeferredStartup(tt.give))
+			for _, event := range append(startup, tt.end...) {
+				l.LogEvent(event)
+			}
+
+			assert.Equal(t, tt.want, lines(observedLogs.TakeAll()))
+
+			l.LogEvent(&fxevent.OnStopExecuting{FunctionName: "hook.onStop", CallerName: "main.new"})
+			assert.Equal(t, []line{{"debug", "OnStop hook executing", nil}}, lines(observedLogs.TakeAll()),
+				"events should be logged once started")
+		})
+	}
+
+	t.Run("summary", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithDeferredStartup(DeferredStartup{MaxEvents: 2, Summarize: true}))
+		for _, event := range startup {
+			l.LogEvent(event)
+		}
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, map[string]any{"discarded": float64(2), "dropped": float64(1)}, logs[0].Fields())
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithDeferredStartup(DeferredStartup{
+			MaxBytes:  eventSize(startup[1]) + eventSize(startup[2]),
+			Summarize: true,
+		}))
+		for _, event := range startup {
+			l.LogEvent(event)
+		}
+		l.LogEvent(&fxevent.Provided{StackTrace: []string{strings.Repeat("x", 1<<20)}})
+		l.LogEvent(&fxevent.RollingBack{StartErr: someError})
+
+		assert.Equal(t, []line{
+			{"error", "invoking", true},
+			{"error", "OnStart hook executing", true},
+			{"error", "start failed, rolling back", nil},
+		}, lines(observedLogs.TakeAll()), "events over the budget should be dropped")
+	})
+
+	t.Run("field names", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithDeferredStartup(DeferredStartup{Summarize: true}), WithECS(), WithSequence())
+		l.now = fakeClock(time.Millisecond)
+		l.LogEvent(&fxevent.Provided{OutputTypeNames: []string{"fx.Lifecycle"}})
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "deferred events discarded", logs[0].Message())
+		assert.Equal(t, map[string]any{
+			"event.action":   "started",
+			"event.outcome":  "success",
+			"event.sequence": float64(2),
+			"fx.elapsed":     float64(time.Millisecond),
+			"fx.discarded":   float64(1),
+			"fx.dropped":     float64(0),
+		}, logs[0].Fields())
+
+		l = New(core, WithDeferredStartup(DeferredStartup{}), WithECS())
+		l.LogEvent(&fxevent.Provided{OutputTypeNames: []string{"fx.Lifecycle"}})
+		l.LogEvent(&fxevent.RollingBack{StartErr: someError})
+
+		logs = observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, true, logs[0].Fields()["fx.replayed"])
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithDeferredStartup(DeferredStartup{}),
+			WithFilter(MatchEventType((*fxevent.Invoking)(nil), (*fxevent.RollingBack)(nil))),
+		)
+		for _, event := range startup {
+			l.LogEvent(event)
+		}
+		l.LogEvent(&fxevent.RollingBack{StartErr: someError})
+
+		assert.Equal(t, []line{
+			{"error", "provided", true},
+			{"error", "OnStart hook executing", true},
+		}, lines(observedLogs.TakeAll()))
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithDeferredStartup(DeferredStartup{}),
+			WithSlowThresholds(SlowThresholds{OnStart: time.Second, Run: time.Second}),
+		)
+		l.LogEvent(&fxevent.Run{Name: "main.new", Kind: "provide", Runtime: time.Millisecond})
+		l.LogEvent(&fxevent.Run{Name: "main.slow", Kind: "provide", Runtime: time.Hour})
+		l.LogEvent(&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.new", Runtime: time.Hour})
+
+		assert.Equal(t, []line{
+			{"warn", "run", nil},
+			{"warn", "OnStart hook executed", nil},
+		}, lines(observedLogs.TakeAll()), "slow events should be logged as they come")
+
+		l.LogEvent(&fxevent.RollingBack{StartErr: someError})
+		assert.Equal(t, []line{
+			{"error", "run", true},
+			{"error", "start failed, rolling back", nil},
+		}, lines(observedLogs.TakeAll()))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.PanicsWithError(t, "fxzerolog: invalid max deferred events -1", func() {
+			New(zerolog.Nop(), WithDeferredStartup(DeferredStartup{MaxEvents: -1}))
+		})
+		assert.PanicsWithError(t, "fxzerolog: invalid max deferred bytes -1", func() {
+			New(zerolog.Nop(), WithDeferredStartup(DeferredStartup{MaxBytes: -1}))
+		})
+	})
+}
+
+func TestDeferredRing(t *testing.T) {
+	t.Parallel()
+
+	var r deferredRing
+	var want []deferredEvent
+	for i := 0; i < 40; i++ {
+		e := deferredEvent{info: eventInfo{seq: uint64(i)}, size: i}
+		r.push(e)
+		want = append(want, e)
+		if i%3 == 0 {
+			r.pop()
+			want = want[1:]
+		}
+	}
+
+	assert.Equal(t, want, r.all())
+	assert.Equal(t, len(want), r.len())
+	var size int
+	for _, e := range want {
+		size += e.size
+	}
+	assert.Equal(t, size, r.size)
+}
+
+func TestEventSize(t *testing.T) {
+	t.Parallel()
+
+	small := eventSize(&fxevent.Provided{})
+	large := eventSize(&fxevent.Provided{
+		ConstructorName: "main.new",
+		StackTrace:      []string{"main.main (/app/main.go:10)"},
+		ModuleTrace:     []string{"main.main (/app/main.go:8)"},
+		OutputTypeNames: []string{"*main.A"},
+	})
+	assert.Positive(t, small)
+	assert.Greater(t, large, small+len("main.new")+len("main.main (/app/main.go:10)"))
+}
@@ -0,0 +1,99 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// durationFormat describes how durations such as hook runtimes are encoded.
+type durationFormat struct {
+	// unit is the unit of numeric durations. Zero encodes durations as
+	// strings such as "42.71µs".
+	unit time.Duration
+	// float encodes numeric durations as floats instead of integers.
+	float bool
+}
+
+// WithDurationUnit encodes durations, such as the runtime of hooks and
+// constructors, as numbers of unit instead of strings such as "42.71µs".
+// The unit must be one of time.Nanosecond, time.Microsecond,
+// time.Millisecond and time.Second. Numbers are integers, truncated towards
+// zero, unless WithFloatDurations is used.
+//
+// Like zerolog's Event.Dur, but without relying on the global
+// zerolog.DurationFieldUnit and zerolog.DurationFieldInteger.
+func WithDurationUnit(unit time.Duration) Option {
+	return func(l *ZerologLogger) {
+		l.durations.unit = unit
+	}
+}
+
+// WithFloatDurations encodes numeric durations as floats, e.g. 1.5 for one
+// and a half second in time.Second unit. Defaults to time.Millisecond when
+// no unit is set with WithDurationUnit.
+func WithFloatDurations() Option {
+	return func(l *ZerologLogger) {
+		if l.durations.unit == 0 {
+			l.durations.unit = time.Millisecond
+		}
+		l.durations.float = true
+	}
+}
+
+// WithHumanRuntime adds the runtime as a human-readable string, such as
+// "42.71µs", under the FieldNames.RuntimeHuman key next to every runtime.
+// Useful along with numeric durations for people reading raw logs.
+func WithHumanRuntime() Option {
+	return func(l *ZerologLogger) {
+		l.humanRuntime = true
+	}
+}
+
+func (f durationFormat) validate() error {
+	switch f.unit {
+	case 0, time.Nanosecond, time.Microsecond, time.Millisecond, time.Second:
+		return nil
+	}
+
+	return fmt.Errorf("fxzerolog: invalid duration unit %v", f.unit)
+}
+
+func (l *ZerologLogger) durationField(key string, d time.Duration) zerolog.LogObjectMarshaler {
+	return durationField{key: key, d: d, format: l.durations}
+}
+
+func (l *ZerologLogger) runtimeField(d time.Duration) zerolog.LogObjectMarshaler {
+	f := l.fieldNames()
+	field := durationField{key: f.Runtime, d: d, format: l.durations}
+	if l.humanRuntime {
+		field.humanKey = f.RuntimeHuman
+	}
+
+	return field
+}
+
+// durationField encodes a duration according to a durationFormat, along
+// with its string form under humanKey when set.
+type durationField struct {
+	key      string
+	humanKey string
+	d        time.Duration
+	format   durationFormat
+}
+
+func (f durationField) MarshalZerologObject(e *zerolog.Event) {
+	switch {
+	case f.format.unit <= 0:
+		e.Str(f.key, f.d.String())
+	case f.format.float:
+		e.Float64(f.key, float64(f.d)/float64(f.format.unit))
+	default:
+		e.Int64(f.key, int64(f.d/f.format.unit))
+	}
+
+	if len(f.humanKey) > 0 {
+		e.Str(f.humanKey, f.d.String())
+	}
+}
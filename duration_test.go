@@ -0,0 +1,64 @@
+package fxzerolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestDurations(t *testing.T) {
+	runtime := 1500*time.Millisecond + 250*time.Microsecond
+
+	tests := []struct {
+		name string
+		give []Option
+		want any
+	}{
+		{name: "string", want: "1.50025s"},
+		{name: "nanoseconds", give: []Option{WithDurationUnit(time.Nanosecond)}, want: float64(1500250000)},
+		{name: "microseconds", give: []Option{WithDurationUnit(time.Microsecond)}, want: float64(1500250)},
+		{name: "milliseconds", give: []Option{WithDurationUnit(time.Millisecond)}, want: float64(1500)},
+		{name: "seconds", give: []Option{WithDurationUnit(time.Second)}, want: float64(1)},
+		{name: "float milliseconds", give: []Option{WithFloatDurations()}, want: 1500.25},
+		{name: "float seconds", give: []Option{WithFloatDurations(), WithDurationUnit(time.Second)}, want: 1.50025},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+			l := New(core, tt.give...)
+			l.LogEvent(&fxevent.OnStartExecuted{Runtime: runtime})
+			l.LogEvent(&fxevent.OnStopExecuted{Runtime: runtime})
+			l.LogEvent(&fxevent.Run{Runtime: runtime})
+
+			logs := observedLogs.TakeAll()
+			require.Len(t, logs, 3)
+			for _, entry := range logs {
+				assert.Equal(t, tt.want, entry.Fields()["runtime"], entry.Message())
+				assert.NotContains(t, entry.Fields(), "runtime_human")
+			}
+		})
+	}
+
+	t.Run("human runtime", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithDurationUnit(time.Millisecond), WithHumanRuntime())
+		l.LogEvent(&fxevent.Run{Runtime: runtime})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, float64(1500), logs[0].Fields()["runtime"])
+		assert.Equal(t, "1.50025s", logs[0].Fields()["runtime_human"])
+	})
+
+	t.Run("invalid unit", func(t *testing.T) {
+		core, _ := newZerologObservableLogger(zerolog.TraceLevel)
+		assert.PanicsWithError(t, "fxzerolog: invalid duration unit 1m0s", func() {
+			New(core, WithDurationUnit(time.Minute))
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package fxzerolog
+
+import (
+	"time"
+)
+
+// ECSFieldNames returns the field names used by WithECS. Fields without an
+// Elastic Common Schema equivalent are namespaced under "fx.".
+func ECSFieldNames() FieldNames {
+	return ecsFieldNames
+}
+
+var ecsFieldNames = FieldNames{
+	Callee:              "log.origin.function",
+	Caller:              "fx.caller",
+	Runtime:             "event.duration",
+	RuntimeHuman:        "fx.runtime_human",
+	Slow:                "fx.slow",
+	Threshold:           "fx.threshold",
+	HookID:              "fx.hook_id",
+	RunID:               "fx.run_id",
+	Seq:                 "event.sequence",
+	Elapsed:             "fx.elapsed",
+	Phase:               "fx.phase",
+	StackTrace:          "fx.stacktrace",
+	ModuleTrace:         "fx.moduletrace",
+	Module:              "fx.module",
+	Type:                "fx.type",
+	Types:               "fx.types",
+	Constructor:         "log.origin.function",
+	Decorator:           "log.origin.function",
+	Function:            "log.origin.function",
+	Stack:               "error.stack_trace",
+	Name:                "log.origin.function",
+	Kind:                "fx.kind",
+	Private:             "fx.private",
+	Signal:              "fx.signal",
+	Startup:             "fx.startup",
+	Provided:            "fx.provided",
+	Decorated:           "fx.decorated",
+	Replaced:            "fx.replaced",
+	Supplied:            "fx.supplied",
+	Constructors:        "fx.constructors",
+	ConstructorRuntime:  "fx.constructor_runtime",
+	SlowestConstructors: "fx.slowest_constructors",
+	SlowestHooks:        "fx.slowest_hooks",
+	Modules:             "fx.modules",
+	Shutdown:            "fx.shutdown",
+	Hooks:               "fx.hooks",
+	FailedHooks:         "fx.failed_hooks",
+	Budget:              "fx.budget",
+	OverBudget:          "fx.over_budget",
+	Status:              "fx.status",
+	Replayed:            "fx.replayed",
+	Discarded:           "fx.discarded",
+	Dropped:             "fx.dropped",
+	Error:               "error.message",
+	ErrorType:           "error.type",
+	Service:             "service.name",
+	Action:              "event.action",
+	Outcome:             "event.outcome",
+}
+
+// WithECS makes the logger emit fields following the Elastic Common Schema:
+// keys are set to ECSFieldNames() and durations are encoded as integer
+// nanoseconds, as ECS expects for event.duration.
+//
+// The level, message and timestamp keys are global to zerolog; set
+// zerolog.LevelFieldName to "log.level" and zerolog.TimestampFieldName to
+// "@timestamp" for fully ECS-compliant lines.
+func WithECS() Option {
+	return func(l *ZerologLogger) {
+		names := ecsFieldNames.withDefaults(&defaultFieldNames)
+		l.fields = &names
+		l.durations = durationFormat{unit: time.Nanosecond}
+	}
+}
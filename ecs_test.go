@@ -0,0 +1,330 @@
+package fxzerolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithECS(t *testing.T) {
+	// One case per event returned by allEvents, in the same order.
+	tests := []struct {
+		wantMessage string
+		wantFields  map[string]any
+	}{
+		{
+			wantMessage: "OnStart hook executing",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "on-start-executing",
+				"event.outcome":       "unknown",
+				"log.origin.function": "hook.onStart",
+				"fx.caller":           "main.main",
+			},
+		},
+		{
+			wantMessage: "OnStart hook executed",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "on-start-executed",
+				"event.outcome":       "success",
+				"log.origin.function": "hook.onStart",
+				"fx.caller":           "main.main",
+				"event.duration":      float64(1000000),
+			},
+		},
+		{
+			wantMessage: "OnStart hook failed",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "on-start-executed",
+				"event.outcome":       "failure",
+				"log.origin.function": "hook.onStart",
+				"fx.caller":           "main.main",
+				"error.message":       "some error",
+				"error.type":          "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "OnStop hook executing",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "on-stop-executing",
+				"event.outcome":       "unknown",
+				"log.origin.function": "hook.onStop",
+				"fx.caller":           "main.main",
+			},
+		},
+		{
+			wantMessage: "OnStop hook executed",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "on-stop-executed",
+				"event.outcome":       "success",
+				"log.origin.function": "hook.onStop",
+				"fx.caller":           "main.main",
+				"event.duration":      float64(1000000),
+			},
+		},
+		{
+			wantMessage: "OnStop hook failed",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "on-stop-executed",
+				"event.outcome":       "failure",
+				"log.origin.function": "hook.onStop",
+				"fx.caller":           "main.main",
+				"error.message":       "some error",
+				"error.type":          "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "supplied",
+			wantFields: map[string]any{
+				"service.name":   "fx",
+				"event.action":   "supplied",
+				"event.outcome":  "success",
+				"fx.type":        "*bytes.Buffer",
+				"fx.stacktrace":  []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace": []any{"main.main (/app/main.go:12)"},
+				"fx.module":      "myModule",
+			},
+		},
+		{
+			wantMessage: "error encountered while applying options",
+			wantFields: map[string]any{
+				"service.name":   "fx",
+				"event.action":   "supplied",
+				"event.outcome":  "failure",
+				"fx.type":        "*bytes.Buffer",
+				"fx.stacktrace":  []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace": []any{"main.main (/app/main.go:12)"},
+				"fx.module":      "myModule",
+				"error.message":  "some error",
+				"error.type":     "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "provided",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "provided",
+				"event.outcome":       "success",
+				"log.origin.function": "bytes.NewBuffer()",
+				"fx.stacktrace":       []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace":      []any{"main.main (/app/main.go:12)"},
+				"fx.module":           "myModule",
+				"fx.type":             "*bytes.Buffer",
+				"fx.private":          true,
+			},
+		},
+		{
+			wantMessage: "error encountered while applying options",
+			wantFields: map[string]any{
+				"service.name":   "fx",
+				"event.action":   "provided",
+				"event.outcome":  "failure",
+				"fx.stacktrace":  []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace": []any{"main.main (/app/main.go:12)"},
+				"error.message":  "some error",
+				"error.type":     "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "replaced",
+			wantFields: map[string]any{
+				"service.name":   "fx",
+				"event.action":   "replaced",
+				"event.outcome":  "success",
+				"fx.stacktrace":  []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace": []any{"main.main (/app/main.go:12)"},
+				"fx.module":      "myModule",
+				"fx.type":        "*bytes.Buffer",
+			},
+		},
+		{
+			wantMessage: "error encountered while replacing",
+			wantFields: map[string]any{
+				"service.name":   "fx",
+				"event.action":   "replaced",
+				"event.outcome":  "failure",
+				"fx.stacktrace":  []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace": []any{"main.main (/app/main.go:12)"},
+				"fx.module":      "myModule",
+				"error.message":  "some error",
+				"error.type":     "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "decorated",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "decorated",
+				"event.outcome":       "success",
+				"log.origin.function": "bytes.NewBuffer()",
+				"fx.stacktrace":       []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace":      []any{"main.main (/app/main.go:12)"},
+				"fx.module":           "myModule",
+				"fx.type":             "*bytes.Buffer",
+			},
+		},
+		{
+			wantMessage: "error encountered while applying options",
+			wantFields: map[string]any{
+				"service.name":   "fx",
+				"event.action":   "decorated",
+				"event.outcome":  "failure",
+				"fx.stacktrace":  []any{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+				"fx.moduletrace": []any{"main.main (/app/main.go:12)"},
+				"fx.module":      "myModule",
+				"error.message":  "some error",
+				"error.type":     "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "run",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "run",
+				"event.outcome":       "success",
+				"log.origin.function": "bytes.NewBuffer()",
+				"fx.kind":             "provide",
+				"event.duration":      float64(1000000),
+				"fx.module":           "myModule",
+			},
+		},
+		{
+			wantMessage: "error returned",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "run",
+				"event.outcome":       "failure",
+				"log.origin.function": "bytes.NewBuffer()",
+				"fx.kind":             "provide",
+				"fx.module":           "myModule",
+				"error.message":       "some error",
+				"error.type":          "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "invoking",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "invoking",
+				"event.outcome":       "unknown",
+				"log.origin.function": "main.run()",
+				"fx.module":           "myModule",
+			},
+		},
+		{
+			wantMessage: "invoke failed",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "invoked",
+				"event.outcome":       "failure",
+				"error.message":       "some error",
+				"error.type":          "*errors.errorString",
+				"error.stack_trace":   "main.main()\n\t/app/main.go:12\n",
+				"log.origin.function": "main.run()",
+				"fx.module":           "myModule",
+				"event.duration":      float64(1000000),
+			},
+		},
+		{
+			wantMessage: "received signal",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "stopping",
+				"event.outcome": "success",
+				"fx.signal":     "INTERRUPT",
+			},
+		},
+		{
+			wantMessage: "stop failed",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "stopped",
+				"event.outcome": "failure",
+				"error.message": "some error",
+				"error.type":    "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "start failed, rolling back",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "rolling-back",
+				"event.outcome": "failure",
+				"error.message": "some error",
+				"error.type":    "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "rollback failed",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "rolled-back",
+				"event.outcome": "failure",
+				"error.message": "some error",
+				"error.type":    "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "start failed",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "started",
+				"event.outcome": "failure",
+				"error.message": "some error",
+				"error.type":    "*errors.errorString",
+			},
+		},
+		{
+			wantMessage: "started",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "started",
+				"event.outcome": "success",
+			},
+		},
+		{
+			wantMessage: "initialized custom fxevent.Logger",
+			wantFields: map[string]any{
+				"service.name":        "fx",
+				"event.action":        "logger-initialized",
+				"event.outcome":       "success",
+				"log.origin.function": "main.newLogger()",
+			},
+		},
+		{
+			wantMessage: "custom logger initialization failed",
+			wantFields: map[string]any{
+				"service.name":  "fx",
+				"event.action":  "logger-initialized",
+				"event.outcome": "failure",
+				"error.message": "some error",
+				"error.type":    "*errors.errorString",
+			},
+		},
+	}
+
+	core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+	l := New(core, WithService("fx"), WithECS())
+	l.now = fakeClock(time.Millisecond)
+
+	events := allEvents()
+	require.Len(t, tests, len(events))
+	for _, event := range events {
+		l.LogEvent(event)
+	}
+
+	logs := observedLogs.TakeAll()
+	require.Len(t, logs, len(tests))
+	for i, tt := range tests {
+		assert.Equal(t, tt.wantMessage, logs[i].Message())
+		assert.Equal(t, tt.wantFields, logs[i].Fields(), "%T", events[i])
+	}
+}
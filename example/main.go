@@ -12,7 +12,7 @@ func main() {
 	fx.New(
 		fx.Provide(func() zerolog.Logger { return log.Logger }),
 		fx.WithLogger(func(log zerolog.Logger) fxevent.Logger {
-			return &fxzerolog.ZerologLogger{Logger: log.With().Str("service", "fx").Logger()}
+			return fxzerolog.New(log.With().Str("service", "fx").Logger())
 		}),
 	).Run()
 }
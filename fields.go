@@ -0,0 +1,402 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx/fxevent"
+)
+
+// FieldNames are the keys of the fields emitted by ZerologLogger.
+//
+// Empty names fall back to the corresponding DefaultFieldNames.
+type FieldNames struct {
+	// Callee is the name of an executed OnStart or OnStop hook.
+	Callee string
+	// Caller is the name of the function that registered a hook.
+	Caller string
+	// Runtime is how long a hook or constructor took to run.
+	Runtime string
+	// RuntimeHuman is the human-readable runtime added by WithHumanRuntime.
+	RuntimeHuman string
+	// Slow marks events slower than their threshold, see WithSlowThresholds.
+	Slow string
+	// Threshold is the runtime threshold breached by slow events.
+	Threshold string
+	// HookID pairs the executing and executed events of a hook or invoke,
+	// see WithHookIDs.
+	HookID string
+	// RunID identifies the application run, see WithRunID.
+	RunID string
+	// Seq is the number of an event, see WithSequence.
+	Seq string
+	// Elapsed is the time elapsed since the first event, see WithSequence.
+	Elapsed string
+	// Phase is the lifecycle phase of the application, see WithPhase.
+	Phase string
+	// StackTrace is the stack trace of a supplied, provided, replaced or
+	// decorated type.
+	StackTrace string
+	// ModuleTrace is the trace of the modules of a supplied, provided,
+	// replaced or decorated type.
+	ModuleTrace string
+	// Module is the name of the Fx module of an event.
+	Module string
+	// Type is the name of a supplied, provided, replaced or decorated type.
+	Type string
+	// Types are the names of the types provided, replaced or decorated
+	// together, see WithAggregatedTypes.
+	Types string
+	// Constructor is the name of the constructor of a provided type.
+	Constructor string
+	// Decorator is the name of the decorator of a decorated type.
+	Decorator string
+	// Function is the name of an invoked function or of the constructor of
+	// the custom Fx event logger.
+	Function string
+	// Stack is the stack trace of a failed invoke.
+	Stack string
+	// Name is the name of a run constructor or decorator.
+	Name string
+	// Kind is the kind of a run function, such as "provide".
+	Kind string
+	// Private marks types provided privately to their module.
+	Private string
+	// Signal is the name of the signal received when stopping.
+	Signal string
+	// Startup is the time the application took to start, see WithStartupSummary.
+	Startup string
+	// Provided is the number of types provided, see WithStartupSummary.
+	Provided string
+	// Decorated is the number of types decorated, see WithStartupSummary.
+	Decorated string
+	// Replaced is the number of types replaced, see WithStartupSummary.
+	Replaced string
+	// Supplied is the number of values supplied, see WithStartupSummary.
+	Supplied string
+	// Constructors is the number of constructors run, see WithStartupSummary.
+	Constructors string
+	// ConstructorRuntime is the cumulative runtime of the constructors, see
+	// WithStartupSummary.
+	ConstructorRuntime string
+	// SlowestConstructors are the slowest constructors, see
+	// WithStartupSummary.
+	SlowestConstructors string
+	// SlowestHooks are the slowest OnStart hooks, see WithStartupSummary.
+	SlowestHooks string
+	// Modules are the names of the modules seen, see WithStartupSummary.
+	Modules string
+	// Shutdown is the time the application took to stop, see
+	// WithShutdownSummary.
+	Shutdown string
+	// Hooks are the OnStop hooks run, see WithShutdownSummary.
+	Hooks string
+	// FailedHooks are the names of the OnStop hooks that failed, see
+	// WithShutdownSummary.
+	FailedHooks string
+	// Budget is the shutdown budget, see WithShutdownSummary.
+	Budget string
+	// OverBudget marks shutdowns longer than their budget, see
+	// WithShutdownSummary.
+	OverBudget string
+	// Status is the status, ok or failed, of each hook of the shutdown
+	// summary, see WithShutdownSummary.
+	Status string
+	// Replayed marks the lines replayed, see WithDeferredStartup.
+	Replayed string
+	// Discarded is the number of events discarded, see WithDeferredStartup.
+	Discarded string
+	// Dropped is the number of events dropped because of
+	// DeferredStartup.MaxEvents, see WithDeferredStartup.
+	Dropped string
+	// Namespace is the package, and receiver for methods, of the function
+	// names emitted under the Callee, Constructor, Decorator, Function and
+	// Name keys. When set, it is split from those names, so that
+	// "go.uber.org/fx.New.func1()" is emitted as "New.func1" with the
+	// namespace "go.uber.org/fx". Not emitted unless set.
+	Namespace string
+	// Error is the error message of failed events. Defaults to
+	// zerolog.ErrorFieldName at the time the event is logged.
+	Error string
+	// ErrorType is the Go type of the error of failed events. Not emitted
+	// unless set.
+	ErrorType string
+	// Service is the name of the service set with WithService.
+	Service string
+	// Action is the kebab-cased name of the event type, such as
+	// "on-start-executed". Not emitted unless set.
+	Action string
+	// Outcome is "success" or "failure" depending on whether the event
+	// reports an error, or "unknown" for hooks and invokes that have not
+	// returned yet. Not emitted unless set.
+	Outcome string
+}
+
+// DefaultFieldNames returns the keys emitted by ZerologLogger unless
+// configured otherwise with WithFieldNames.
+func DefaultFieldNames() FieldNames {
+	return defaultFieldNames
+}
+
+var defaultFieldNames = FieldNames{
+	Callee:              "callee",
+	Caller:              "caller",
+	Runtime:             "runtime",
+	RuntimeHuman:        "runtime_human",
+	Slow:                "slow",
+	Threshold:           "threshold",
+	HookID:              "hook_id",
+	RunID:               "run_id",
+	Seq:                 "seq",
+	Elapsed:             "elapsed",
+	Phase:               "phase",
+	StackTrace:          "stacktrace",
+	ModuleTrace:         "moduletrace",
+	Module:              "module",
+	Type:                "type",
+	Types:               "types",
+	Constructor:         "constructor",
+	Decorator:           "decorator",
+	Function:            "function",
+	Stack:               "stack",
+	Name:                "name",
+	Kind:                "kind",
+	Private:             "private",
+	Signal:              "signal",
+	Startup:             "startup",
+	Provided:            "provided",
+	Decorated:           "decorated",
+	Replaced:            "replaced",
+	Supplied:            "supplied",
+	Constructors:        "constructors",
+	ConstructorRuntime:  "constructor_runtime",
+	SlowestConstructors: "slowest_constructors",
+	SlowestHooks:        "slowest_hooks",
+	Modules:             "modules",
+	Shutdown:            "shutdown",
+	Hooks:               "hooks",
+	FailedHooks:         "failed_hooks",
+	Budget:              "budget",
+	OverBudget:          "over_budget",
+	Status:              "status",
+	Replayed:            "replayed",
+	Discarded:           "discarded",
+	Dropped:             "dropped",
+	Service:             "service",
+}
+
+// WithFieldNames sets the keys of the fields emitted by the logger, e.g. to
+// namespace them with FieldNames{Callee: "fx.callee", Module: "fx.module"}.
+// Empty names keep their default.
+func WithFieldNames(names FieldNames) Option {
+	return func(l *ZerologLogger) {
+		merged := names.withDefaults(l.fieldNames())
+		l.fields = &merged
+	}
+}
+
+func (l *ZerologLogger) fieldNames() *FieldNames {
+	if l.fields == nil {
+		return &defaultFieldNames
+	}
+
+	return l.fields
+}
+
+func (names FieldNames) withDefaults(defaults *FieldNames) FieldNames {
+	orDefault := func(name *string, def string) {
+		if len(*name) == 0 {
+			*name = def
+		}
+	}
+
+	orDefault(&names.Callee, defaults.Callee)
+	orDefault(&names.Caller, defaults.Caller)
+	orDefault(&names.Runtime, defaults.Runtime)
+	orDefault(&names.RuntimeHuman, defaults.RuntimeHuman)
+	orDefault(&names.Slow, defaults.Slow)
+	orDefault(&names.Threshold, defaults.Threshold)
+	orDefault(&names.HookID, defaults.HookID)
+	orDefault(&names.RunID, defaults.RunID)
+	orDefault(&names.Seq, defaults.Seq)
+	orDefault(&names.Elapsed, defaults.Elapsed)
+	orDefault(&names.Phase, defaults.Phase)
+	orDefault(&names.StackTrace, defaults.StackTrace)
+	orDefault(&names.ModuleTrace, defaults.ModuleTrace)
+	orDefault(&names.Module, defaults.Module)
+	orDefault(&names.Type, defaults.Type)
+	orDefault(&names.Types, defaults.Types)
+	orDefault(&names.Constructor, defaults.Constructor)
+	orDefault(&names.Decorator, defaults.Decorator)
+	orDefault(&names.Function, defaults.Function)
+	orDefault(&names.Stack, defaults.Stack)
+	orDefault(&names.Name, defaults.Name)
+	orDefault(&names.Kind, defaults.Kind)
+	orDefault(&names.Private, defaults.Private)
+	orDefault(&names.Signal, defaults.Signal)
+	orDefault(&names.Startup, defaults.Startup)
+	orDefault(&names.Provided, defaults.Provided)
+	orDefault(&names.Decorated, defaults.Decorated)
+	orDefault(&names.Replaced, defaults.Replaced)
+	orDefault(&names.Supplied, defaults.Supplied)
+	orDefault(&names.Constructors, defaults.Constructors)
+	orDefault(&names.ConstructorRuntime, defaults.ConstructorRuntime)
+	orDefault(&names.SlowestConstructors, defaults.SlowestConstructors)
+	orDefault(&names.SlowestHooks, defaults.SlowestHooks)
+	orDefault(&names.Modules, defaults.Modules)
+	orDefault(&names.Shutdown, defaults.Shutdown)
+	orDefault(&names.Hooks, defaults.Hooks)
+	orDefault(&names.FailedHooks, defaults.FailedHooks)
+	orDefault(&names.Budget, defaults.Budget)
+	orDefault(&names.OverBudget, defaults.OverBudget)
+	orDefault(&names.Status, defaults.Status)
+	orDefault(&names.Replayed, defaults.Replayed)
+	orDefault(&names.Discarded, defaults.Discarded)
+	orDefault(&names.Dropped, defaults.Dropped)
+	orDefault(&names.Namespace, defaults.Namespace)
+	orDefault(&names.Error, defaults.Error)
+	orDefault(&names.ErrorType, defaults.ErrorType)
+	orDefault(&names.Service, defaults.Service)
+	orDefault(&names.Action, defaults.Action)
+	orDefault(&names.Outcome, defaults.Outcome)
+
+	return names
+}
+
+func (l *ZerologLogger) errorField(err error) zerolog.LogObjectMarshaler {
+	return errorField{names: l.fieldNames(), err: err}
+}
+
+// errorField encodes the error of a failed event.
+type errorField struct {
+	names *FieldNames
+	err   error
+}
+
+func (f errorField) MarshalZerologObject(e *zerolog.Event) {
+	key := f.names.Error
+	if len(key) == 0 {
+		key = zerolog.ErrorFieldName
+	}
+
+	e.AnErr(key, f.err)
+
+	if len(f.names.ErrorType) > 0 {
+		e.Str(f.names.ErrorType, fmt.Sprintf("%T", f.err))
+	}
+}
+
+func (l *ZerologLogger) functionField(key, name string) zerolog.LogObjectMarshaler {
+	return functionField{names: l.fieldNames(), key: key, name: name}
+}
+
+// functionField encodes the name of a function, split from its namespace
+// when FieldNames.Namespace is set.
+type functionField struct {
+	names *FieldNames
+	key   string
+	name  string
+}
+
+func (f functionField) MarshalZerologObject(e *zerolog.Event) {
+	if len(f.names.Namespace) == 0 {
+		e.Str(f.key, f.name)
+		return
+	}
+
+	namespace, function := splitFunctionName(f.name)
+	e.Str(f.key, function)
+	maybeStringField(e, f.names.Namespace, namespace)
+}
+
+// splitFunctionName splits a Go function name such as
+// "go.uber.org/fx.(*App).run()" into its namespace, "go.uber.org/fx.(*App)",
+// and function, "run". The namespace is the package path, followed by the
+// receiver for methods.
+func splitFunctionName(name string) (namespace, function string) {
+	name = strings.TrimSuffix(name, "()")
+
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return "", name
+	}
+	dot += slash + 1
+
+	namespace, function = name[:dot], name[dot+1:]
+	if strings.HasPrefix(function, "(") {
+		if end := strings.Index(function, ")."); end >= 0 {
+			namespace, function = namespace+"."+function[:end+1], function[end+2:]
+		}
+	}
+
+	return namespace, function
+}
+
+// eventActions maps event types to their kebab-cased names.
+var eventActions = func() map[reflect.Type]string {
+	events := []fxevent.Event{
+		&fxevent.OnStartExecuting{},
+		&fxevent.OnStartExecuted{},
+		&fxevent.OnStopExecuting{},
+		&fxevent.OnStopExecuted{},
+		&fxevent.Supplied{},
+		&fxevent.Provided{},
+		&fxevent.Replaced{},
+		&fxevent.Decorated{},
+		&fxevent.Run{},
+		&fxevent.Invoking{},
+		&fxevent.Invoked{},
+		&fxevent.Stopping{},
+		&fxevent.Stopped{},
+		&fxevent.RollingBack{},
+		&fxevent.RolledBack{},
+		&fxevent.Started{},
+		&fxevent.LoggerInitialized{},
+	}
+
+	actions := make(map[reflect.Type]string, len(events))
+	for _, event := range events {
+		typ := reflect.TypeOf(event)
+		actions[typ] = kebabCase(typ.Elem().Name())
+	}
+
+	return actions
+}()
+
+func eventAction(event fxevent.Event) string {
+	return eventActions[reflect.TypeOf(event)]
+}
+
+// eventOutcome returns the outcome of event: unknown until hooks and invokes
+// have returned.
+func eventOutcome(event fxevent.Event, failed bool) string {
+	switch event.(type) {
+	case *fxevent.OnStartExecuting, *fxevent.OnStopExecuting, *fxevent.Invoking:
+		return "unknown"
+	}
+
+	if failed {
+		return "failure"
+	}
+
+	return "success"
+}
+
+func kebabCase(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteRune('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package fxzerolog
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+// allEvents returns one event of every branch of LogEvent, failed ones included.
+func allEvents() []fxevent.Event {
+	someError := errors.New("some error")
+	stackTrace := []string{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"}
+	moduleTrace := []string{"main.main (/app/main.go:12)"}
+
+	return []fxevent.Event{
+		&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.main"},
+		&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.main", Runtime: time.Millisecond},
+		&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.main", Err: someError},
+		&fxevent.OnStopExecuting{FunctionName: "hook.onStop", CallerName: "main.main"},
+		&fxevent.OnStopExecuted{FunctionName: "hook.onStop", CallerName: "main.main", Runtime: time.Millisecond},
+		&fxevent.OnStopExecuted{FunctionName: "hook.onStop", CallerName: "main.main", Err: someError},
+		&fxevent.Supplied{TypeName: "*bytes.Buffer", StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule"},
+		&fxevent.Supplied{TypeName: "*bytes.Buffer", StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule", Err: someError},
+		&fxevent.Provided{ConstructorName: "bytes.NewBuffer()", StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule", OutputTypeNames: []string{"*bytes.Buffer"}, Private: true},
+		&fxevent.Provided{StackTrace: stackTrace, ModuleTrace: moduleTrace, Err: someError},
+		&fxevent.Replaced{StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule", OutputTypeNames: []string{"*bytes.Buffer"}},
+		&fxevent.Replaced{StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule", Err: someError},
+		&fxevent.Decorated{DecoratorName: "bytes.NewBuffer()", StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule", OutputTypeNames: []string{"*bytes.Buffer"}},
+		&fxevent.Decorated{StackTrace: stackTrace, ModuleTrace: moduleTrace, ModuleName: "myModule", Err: someError},
+		&fxevent.Run{Name: "bytes.NewBuffer()", Kind: "provide", ModuleName: "myModule", Runtime: time.Millisecond},
+		&fxevent.Run{Name: "bytes.NewBuffer()", Kind: "provide", ModuleName: "myModule", Err: someError},
+		&fxevent.Invoking{FunctionName: "main.run()", ModuleName: "myModule"},
+		&fxevent.Invoked{FunctionName: "main.run()", ModuleName: "myModule", Err: someError, Trace: "main.main()\n\t/app/main.go:12\n"},
+		&fxevent.Stopping{Signal: os.Interrupt},
+		&fxevent.Stopped{Err: someError},
+		&fxevent.RollingBack{StartErr: someError},
+		&fxevent.RolledBack{Err: someError},
+		&fxevent.Started{Err: someError},
+		&fxevent.Started{},
+		&fxevent.LoggerInitialized{ConstructorName: "main.newLogger()"},
+		&fxevent.LoggerInitialized{Err: someError},
+	}
+}
+
+func TestWithFieldNames(t *testing.T) {
+	t.Run("namespaced", func(t *testing.T) {
+		defaults := DefaultFieldNames()
+		names := FieldNames{
+			Callee:      "fx." + defaults.Callee,
+			Caller:      "fx." + defaults.Caller,
+			Runtime:     "fx." + defaults.Runtime,
+			StackTrace:  "fx." + defaults.StackTrace,
+			ModuleTrace: "fx." + defaults.ModuleTrace,
+			Module:      "fx." + defaults.Module,
+			Type:        "fx." + defaults.Type,
+			Constructor: "fx." + defaults.Constructor,
+			Decorator:   "fx." + defaults.Decorator,
+			Function:    "fx." + defaults.Function,
+			Stack:       "fx." + defaults.Stack,
+			Name:        "fx." + defaults.Name,
+			Kind:        "fx." + defaults.Kind,
+			Private:     "fx." + defaults.Private,
+			Signal:      "fx." + defaults.Signal,
+			Error:       "fx.error",
+		}
+
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithFieldNames(names))
+		for _, event := range allEvents() {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, len(allEvents()))
+		for _, entry := range logs {
+			for key := range entry.Fields() {
+				assert.True(t, strings.HasPrefix(key, "fx."), "%q in %q", key, entry.Message())
+			}
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithFieldNames(FieldNames{Callee: "hook", Error: "reason"}))
+		l.LogEvent(&fxevent.OnStartExecuted{
+			FunctionName: "hook.onStart",
+			CallerName:   "main.main",
+			Err:          errors.New("some error"),
+		})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, map[string]any{
+			"hook":   "hook.onStart",
+			"caller": "main.main",
+			"reason": "some error",
+		}, logs[0].Fields())
+	})
+
+	t.Run("layered", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithFieldNames(FieldNames{Kind: "fx.kind"}),
+			WithFieldNames(FieldNames{Name: "fx.name"}),
+		)
+		l.LogEvent(&fxevent.Run{Name: "bytes.NewBuffer()", Kind: "provide", Runtime: time.Millisecond})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, map[string]any{
+			"fx.name": "bytes.NewBuffer()",
+			"fx.kind": "provide",
+			"runtime": "1ms",
+		}, logs[0].Fields())
+	})
+}
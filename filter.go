@@ -0,0 +1,196 @@
+package fxzerolog
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// Filter reports whether an Fx event should be dropped instead of logged.
+type Filter func(fxevent.Event) bool
+
+// WithFilter drops the events matched by any of filters. Dropped events are
+// discarded before any zerolog event is allocated for them.
+//
+// The option may be given multiple times; an event is dropped as soon as one
+// of the filters matches it.
+func WithFilter(filters ...Filter) Option {
+	return func(l *ZerologLogger) {
+		l.filters = append(l.filters, filters...)
+	}
+}
+
+// MatchAll returns a Filter that matches events matched by all of filters,
+// e.g. MatchAll(MatchEventType((*fxevent.Provided)(nil)), MatchModule("db")).
+func MatchAll(filters ...Filter) Filter {
+	return func(event fxevent.Event) bool {
+		for _, filter := range filters {
+			if !filter(event) {
+				return false
+			}
+		}
+
+		return len(filters) > 0
+	}
+}
+
+// MatchEventType returns a Filter that matches events of the same type as
+// any of events. The events are only used for their type, so typed nils such
+// as (*fxevent.Provided)(nil) work.
+func MatchEventType(events ...fxevent.Event) Filter {
+	types := make(map[reflect.Type]struct{}, len(events))
+	for _, event := range events {
+		types[reflect.TypeOf(event)] = struct{}{}
+	}
+
+	return func(event fxevent.Event) bool {
+		_, ok := types[reflect.TypeOf(event)]
+		return ok
+	}
+}
+
+// MatchModule returns a Filter that matches events emitted for any of the
+// named Fx modules. Use an empty name to match events of the root module.
+func MatchModule(names ...string) Filter {
+	return func(event fxevent.Event) bool {
+		module, ok := eventModule(event)
+		return ok && contains(names, module)
+	}
+}
+
+// MatchFunction returns a Filter that matches events whose constructor,
+// decorator, hook or function name matches the glob pattern, where '*'
+// matches any sequence of characters and '?' matches a single character,
+// e.g. "go.uber.org/fx.*".
+func MatchFunction(pattern string) Filter {
+	re := compileGlob(pattern)
+
+	return func(event fxevent.Event) bool {
+		function, ok := eventFunction(event)
+		return ok && re.MatchString(function)
+	}
+}
+
+// MatchOutputType returns a Filter that matches supplied, provided, replaced
+// and decorated events for which any of the output types is one of names,
+// e.g. MatchOutputType("fx.Lifecycle", "fx.Shutdowner", "fx.DotGraph").
+func MatchOutputType(names ...string) Filter {
+	return func(event fxevent.Event) bool {
+		for _, typ := range eventOutputTypes(event) {
+			if contains(names, typ) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func (l *ZerologLogger) filtered(event fxevent.Event) bool {
+	for _, filter := range l.filters {
+		if filter(event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compileGlob(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// eventModule returns the name of the module an event was emitted for, and
+// whether the event carries a module at all.
+func eventModule(event fxevent.Event) (string, bool) {
+	switch e := event.(type) {
+	case *fxevent.Supplied:
+		return e.ModuleName, true
+	case *fxevent.Provided:
+		return e.ModuleName, true
+	case *fxevent.Replaced:
+		return e.ModuleName, true
+	case *fxevent.Decorated:
+		return e.ModuleName, true
+	case *fxevent.Run:
+		return e.ModuleName, true
+	case *fxevent.Invoking:
+		return e.ModuleName, true
+	case *fxevent.Invoked:
+		return e.ModuleName, true
+	}
+
+	return "", false
+}
+
+// eventFunction returns the name of the function an event is about, and
+// whether the event carries a function at all.
+func eventFunction(event fxevent.Event) (string, bool) {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuting:
+		return e.FunctionName, true
+	case *fxevent.OnStartExecuted:
+		return e.FunctionName, true
+	case *fxevent.OnStopExecuting:
+		return e.FunctionName, true
+	case *fxevent.OnStopExecuted:
+		return e.FunctionName, true
+	case *fxevent.Provided:
+		return e.ConstructorName, true
+	case *fxevent.Decorated:
+		return e.DecoratorName, true
+	case *fxevent.Run:
+		return e.Name, true
+	case *fxevent.Invoking:
+		return e.FunctionName, true
+	case *fxevent.Invoked:
+		return e.FunctionName, true
+	case *fxevent.LoggerInitialized:
+		return e.ConstructorName, true
+	}
+
+	return "", false
+}
+
+// eventOutputTypes returns the names of the types an event makes available
+// to the container.
+func eventOutputTypes(event fxevent.Event) []string {
+	switch e := event.(type) {
+	case *fxevent.Supplied:
+		return []string{e.TypeName}
+	case *fxevent.Provided:
+		return e.OutputTypeNames
+	case *fxevent.Replaced:
+		return e.OutputTypeNames
+	case *fxevent.Decorated:
+		return e.OutputTypeNames
+	}
+
+	return nil
+}
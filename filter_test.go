@@ -0,0 +1,87 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestFilters(t *testing.T) {
+	t.Parallel()
+
+	lifecycle := &fxevent.Provided{
+		ConstructorName: "go.uber.org/fx.New.func1()",
+		OutputTypeNames: []string{"fx.Lifecycle"},
+	}
+	buffer := &fxevent.Provided{
+		ConstructorName: "bytes.NewBuffer()",
+		ModuleName:      "myModule",
+		OutputTypeNames: []string{"*bytes.Buffer", "io.Writer"},
+	}
+	supplied := &fxevent.Supplied{TypeName: "*bytes.Buffer"}
+	run := &fxevent.Run{Name: "go.uber.org/fx.New.func1()", Kind: "provide"}
+	hook := &fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.main"}
+	started := &fxevent.Started{}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		give   fxevent.Event
+		want   bool
+	}{
+		{"MatchEventType", MatchEventType((*fxevent.Provided)(nil)), buffer, true},
+		{"MatchEventType/Other", MatchEventType((*fxevent.Provided)(nil)), started, false},
+		{"MatchEventType/Many", MatchEventType(&fxevent.Run{}, &fxevent.Started{}), started, true},
+		{"MatchModule", MatchModule("myModule"), buffer, true},
+		{"MatchModule/Root", MatchModule(""), lifecycle, true},
+		{"MatchModule/Other", MatchModule("myModule"), lifecycle, false},
+		{"MatchModule/NoModule", MatchModule(""), hook, false},
+		{"MatchFunction", MatchFunction("go.uber.org/fx.*"), lifecycle, true},
+		{"MatchFunction/Run", MatchFunction("go.uber.org/fx.*"), run, true},
+		{"MatchFunction/Hook", MatchFunction("hook.onSta?t"), hook, true},
+		{"MatchFunction/Anchored", MatchFunction("fx.*"), lifecycle, false},
+		{"MatchFunction/NoFunction", MatchFunction("*"), started, false},
+		{"MatchOutputType", MatchOutputType("fx.Lifecycle", "fx.Shutdowner"), lifecycle, true},
+		{"MatchOutputType/AnyOf", MatchOutputType("io.Writer"), buffer, true},
+		{"MatchOutputType/Supplied", MatchOutputType("*bytes.Buffer"), supplied, true},
+		{"MatchOutputType/Other", MatchOutputType("fx.Lifecycle"), buffer, false},
+		{"MatchAll", MatchAll(MatchEventType(&fxevent.Provided{}), MatchModule("myModule")), buffer, true},
+		{"MatchAll/Partial", MatchAll(MatchEventType(&fxevent.Provided{}), MatchModule("myModule")), lifecycle, false},
+		{"MatchAll/Empty", MatchAll(), buffer, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.filter(tt.give))
+		})
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+	l := New(core,
+		WithFilter(MatchOutputType("fx.Lifecycle")),
+		WithFilter(MatchEventType((*fxevent.Invoked)(nil))),
+	)
+
+	l.LogEvent(&fxevent.Provided{OutputTypeNames: []string{"fx.Lifecycle"}})
+	l.LogEvent(&fxevent.Invoked{Err: errors.New("some error")})
+	l.LogEvent(&fxevent.Provided{OutputTypeNames: []string{"*bytes.Buffer"}})
+
+	logs := observedLogs.TakeAll()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "*bytes.Buffer", logs[0].Fields()["type"])
+
+	invoked := &fxevent.Invoked{}
+	allocs := testing.AllocsPerRun(100, func() {
+		l.LogEvent(invoked)
+	})
+	assert.Zero(t, allocs, "filtered events must not allocate")
+}
@@ -1,7 +1,11 @@
 package fxzerolog
 
 import (
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 	"go.uber.org/fx/fxevent"
@@ -13,212 +17,430 @@ var _ fxevent.Logger = (*ZerologLogger)(nil)
 type ZerologLogger struct {
 	Logger zerolog.Logger
 
-	logLevel   zerolog.Level
-	errorLevel *zerolog.Level
+	logLevel         zerolog.Level
+	errorLevel       *zerolog.Level
+	eventLevels      map[reflect.Type]zerolog.Level
+	eventErrorLevels map[reflect.Type]zerolog.Level
+	filters          []Filter
+	fields           *FieldNames
+	service          string
+	durations        durationFormat
+	humanRuntime     bool
+	gcp              bool
+	stacks           stackFormat
+	slow             slowFormat
+	startup          *startupSummary
+	shutdown         *shutdownSummary
+	watchdog         *watchdog
+	hookIDs          *hookIDs
+	runID            string
+	logInvoked       bool
+	sequence         bool
+	logPhase         bool
+	aggregateTypes   bool
+	deferred         *deferredStartup
+	recent           *eventRing
+
+	// mu guards the state accumulated across events below.
+	mu            sync.Mutex
+	now           func() time.Time
+	first         time.Time
+	invokes       pending[time.Time]
+	seq           uint64
+	phase         Phase
+	failedHook    string
+	subscriptions map[*subscription]struct{}
+	dropped       uint64
+	middleware    []Middleware
+
+	// chain is a copy of middleware, read without holding mu.
+	chain atomic.Pointer[[]Middleware]
 }
 
 // UseLogLevel sets the level of non-error logs emitted by Fx to level.
+//
+// Deprecated: Use New with WithLogLevel instead.
 func (l *ZerologLogger) UseLogLevel(level zerolog.Level) {
 	l.logLevel = level
 }
 
 // UseErrorLevel sets the level of error logs emitted by Fx to level.
+//
+// Deprecated: Use New with WithErrorLevel instead.
 func (l *ZerologLogger) UseErrorLevel(level zerolog.Level) {
 	l.errorLevel = &level
 }
 
-func (l *ZerologLogger) logEvent() *zerolog.Event {
-	return l.Logger.WithLevel(l.logLevel)
+func (l *ZerologLogger) logEvent(event fxevent.Event, info eventInfo) *zerolog.Event {
+	return l.newEvent(event, info, l.levelFor(event), false)
+}
+
+func (l *ZerologLogger) errorLogEvent(event fxevent.Event, info eventInfo) *zerolog.Event {
+	return l.newEvent(event, info, l.errorLevelFor(event), true)
 }
 
-func (l *ZerologLogger) errorLogEvent() *zerolog.Event {
+// newEvent starts a zerolog event for event with the fields common to all
+// the lines logged by l.
+func (l *ZerologLogger) newEvent(event fxevent.Event, info eventInfo, level zerolog.Level, failed bool) *zerolog.Event {
+	if info.replay != nil {
+		level = *info.replay
+	}
+
+	zEvent := l.Logger.WithLevel(level)
+	if chain := l.chain.Load(); chain != nil {
+		zEvent = l.handle(*chain, event, zEvent)
+	}
+	if zEvent == nil {
+		return nil
+	}
+
+	f := l.fieldNames()
+	maybeStringField(zEvent, f.Service, l.service)
+	maybeStringField(zEvent, f.RunID, l.runID)
+	if info.seq > 0 {
+		zEvent.Uint64(f.Seq, info.seq).
+			EmbedObject(l.durationField(f.Elapsed, info.elapsed))
+	}
+	if l.logPhase {
+		maybeStringField(zEvent, f.Phase, string(info.phase))
+	}
+	if info.hookID > 0 {
+		zEvent.Uint64(f.HookID, info.hookID)
+	}
+	if len(f.Action) > 0 {
+		zEvent.Str(f.Action, eventAction(event))
+	}
+	if len(f.Outcome) > 0 {
+		zEvent.Str(f.Outcome, eventOutcome(event, failed))
+	}
+	if l.gcp {
+		l.gcpFields(zEvent, event, info, failed)
+	}
+	if info.replay != nil {
+		zEvent.Bool(f.Replayed, true)
+	}
+
+	return zEvent
+}
+
+func (l *ZerologLogger) levelFor(event fxevent.Event) zerolog.Level {
+	if level, ok := l.eventLevels[reflect.TypeOf(event)]; ok {
+		return level
+	}
+
+	return l.logLevel
+}
+
+func (l *ZerologLogger) errorLevelFor(event fxevent.Event) zerolog.Level {
+	if level, ok := l.eventErrorLevels[reflect.TypeOf(event)]; ok {
+		return level
+	}
+
 	if l.errorLevel != nil {
-		return l.Logger.WithLevel(*l.errorLevel)
+		return *l.errorLevel
 	}
 
-	return l.Logger.WithLevel(zerolog.ErrorLevel)
+	return zerolog.ErrorLevel
 }
 
 // LogEvent logs the given event to the provided Zerolog logger.
 func (l *ZerologLogger) LogEvent(event fxevent.Event) {
+	filtered := l.filtered(event)
+
+	var (
+		held  bool
+		flush *deferredFlush
+	)
+	l.mu.Lock()
+	now := l.clock()
+	info := l.observe(event, now)
+	if l.deferred != nil {
+		held, flush = l.deferred.observe(event, info, !filtered && !l.escalated(event))
+	}
+	l.mu.Unlock()
+
+	if l.watchdog != nil {
+		l.watchdog.observe(l, event, info, now)
+	}
+
+	if flush != nil {
+		l.flushDeferred(event, info, flush)
+	}
+
+	if !filtered && !held {
+		l.encode(event, info)
+	}
+
+	l.logSummaries(event, info)
+
+	l.mu.Lock()
+	l.publish(event)
+	l.mu.Unlock()
+}
+
+// eventInfo is what is derived from the events preceding an event.
+type eventInfo struct {
+	// hookID pairs executing and executed events, unless zero.
+	hookID uint64
+	// runtime is the runtime of an invoke, if timed.
+	runtime time.Duration
+	timed   bool
+	// seq is the number of the event, and elapsed the time since the first
+	// one, unless seq is zero.
+	seq     uint64
+	elapsed time.Duration
+	// phase is the lifecycle phase entered with the event.
+	phase Phase
+	// replay is the level of a deferred event replayed, if it is.
+	replay *zerolog.Level
+	// failedHook is the function of the failed OnStart hook a rollback
+	// follows, if known.
+	failedHook string
+}
+
+// observe updates the state accumulated across events with event, received
+// at now, and returns what it tells about event. It must be called with l.mu
+// held.
+func (l *ZerologLogger) observe(event fxevent.Event, now time.Time) eventInfo {
+	var info eventInfo
+	if l.first.IsZero() {
+		l.first = now
+	}
+
+	if l.recent != nil {
+		l.recent.record(RecordedEvent{Time: now, Event: event})
+	}
+
+	l.phase = nextPhase(l.phase, event)
+	info.phase = l.phase
+
+	if l.sequence {
+		l.seq++
+		info.seq = l.seq
+		info.elapsed = now.Sub(l.first)
+	}
+
+	if l.hookIDs != nil {
+		info.hookID = l.hookIDs.observe(event)
+	}
+
+	info.runtime, info.timed = l.timeInvoke(event, now)
+
+	if l.gcp {
+		info.failedHook = l.observeFailedHook(event)
+	}
+
+	if l.startup != nil {
+		l.startup.observe(event)
+	}
+
+	if l.shutdown != nil {
+		l.shutdown.observe(event, now)
+	}
+
+	return info
+}
+
+func (l *ZerologLogger) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+
+	return time.Now()
+}
+
+// encode logs event, using as many lines as it takes.
+func (l *ZerologLogger) encode(event fxevent.Event, info eventInfo) {
+	f := l.fieldNames()
+
 	switch e := event.(type) {
 	case *fxevent.OnStartExecuting:
-		l.logEvent().
-			Str("callee", e.FunctionName).
-			Str("caller", e.CallerName).
+		l.logEvent(event, info).
+			EmbedObject(l.functionField(f.Callee, e.FunctionName)).
+			Str(f.Caller, e.CallerName).
 			Msg("OnStart hook executing")
 	case *fxevent.OnStartExecuted:
 		if e.Err != nil {
-			l.errorLogEvent().
-				Str("callee", e.FunctionName).
-				Str("caller", e.CallerName).
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.functionField(f.Callee, e.FunctionName)).
+				Str(f.Caller, e.CallerName).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("OnStart hook failed")
 		} else {
-			l.logEvent().
-				Str("callee", e.FunctionName).
-				Str("caller", e.CallerName).
-				Str("runtime", e.Runtime.String()).
+			l.timedLogEvent(event, info, e.Runtime).
+				EmbedObject(l.functionField(f.Callee, e.FunctionName)).
+				Str(f.Caller, e.CallerName).
+				EmbedObject(l.runtimeField(e.Runtime)).
 				Msg("OnStart hook executed")
 		}
 	case *fxevent.OnStopExecuting:
-		l.logEvent().
-			Str("callee", e.FunctionName).
-			Str("caller", e.CallerName).
+		l.logEvent(event, info).
+			EmbedObject(l.functionField(f.Callee, e.FunctionName)).
+			Str(f.Caller, e.CallerName).
 			Msg("OnStop hook executing")
 	case *fxevent.OnStopExecuted:
 		if e.Err != nil {
-			l.errorLogEvent().
-				Str("callee", e.FunctionName).
-				Str("caller", e.CallerName).
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.functionField(f.Callee, e.FunctionName)).
+				Str(f.Caller, e.CallerName).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("OnStop hook failed")
 		} else {
-			l.logEvent().
-				Str("callee", e.FunctionName).
-				Str("caller", e.CallerName).
-				Str("runtime", e.Runtime.String()).
+			l.timedLogEvent(event, info, e.Runtime).
+				EmbedObject(l.functionField(f.Callee, e.FunctionName)).
+				Str(f.Caller, e.CallerName).
+				EmbedObject(l.runtimeField(e.Runtime)).
 				Msg("OnStop hook executed")
 		}
 	case *fxevent.Supplied:
 		if e.Err != nil {
-			zEvent := l.errorLogEvent().
-				Str("type", e.TypeName).
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Err(e.Err).
+			zEvent := l.errorLogEvent(event, info).
+				Str(f.Type, e.TypeName).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("error encountered while applying options")
 		} else {
-			zEvent := l.logEvent().
-				Str("type", e.TypeName).
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Err(e.Err).
+			zEvent := l.logEvent(event, info).
+				Str(f.Type, e.TypeName).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
 				Msg("supplied")
 		}
 	case *fxevent.Provided:
-		for _, rtype := range e.OutputTypeNames {
-			zEvent := l.logEvent().
-				Str("constructor", e.ConstructorName).
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Str("type", rtype)
-			maybeBoolField(zEvent, "private", e.Private).
+		for _, types := range l.outputTypes(e.OutputTypeNames) {
+			zEvent := l.logEvent(event, info).
+				EmbedObject(l.functionField(f.Constructor, e.ConstructorName)).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(types)
+			maybeBoolField(zEvent, f.Private, e.Private).
 				Msg("provided")
 		}
 		if e.Err != nil {
-			l.errorLogEvent().
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace).
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace)).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("error encountered while applying options")
 		}
 	case *fxevent.Replaced:
-		for _, rtype := range e.OutputTypeNames {
-			zEvent := l.logEvent().
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Str("type", rtype).
+		for _, types := range l.outputTypes(e.OutputTypeNames) {
+			zEvent := l.logEvent(event, info).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(types).
 				Msg("replaced")
 		}
 		if e.Err != nil {
-			zEvent := l.errorLogEvent().
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Err(e.Err).
+			zEvent := l.errorLogEvent(event, info).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("error encountered while replacing")
 		}
 	case *fxevent.Decorated:
-		for _, rtype := range e.OutputTypeNames {
-			zEvent := l.logEvent().
-				Str("decorator", e.DecoratorName).
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Str("type", rtype).
+		for _, types := range l.outputTypes(e.OutputTypeNames) {
+			zEvent := l.logEvent(event, info).
+				EmbedObject(l.functionField(f.Decorator, e.DecoratorName)).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(types).
 				Msg("decorated")
 		}
 		if e.Err != nil {
-			zEvent := l.errorLogEvent().
-				Strs("stacktrace", e.StackTrace).
-				Strs("moduletrace", e.ModuleTrace)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Err(e.Err).
+			zEvent := l.errorLogEvent(event, info).
+				EmbedObject(l.framesField(f.StackTrace, e.StackTrace)).
+				EmbedObject(l.framesField(f.ModuleTrace, e.ModuleTrace))
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("error encountered while applying options")
 		}
 	case *fxevent.Run:
 		if e.Err != nil {
-			zEvent := l.errorLogEvent().
-				Str("name", e.Name).
-				Str("kind", e.Kind)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Err(e.Err).
+			zEvent := l.errorLogEvent(event, info).
+				EmbedObject(l.functionField(f.Name, e.Name)).
+				Str(f.Kind, e.Kind)
+			maybeStringField(zEvent, f.Module, e.ModuleName).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("error returned")
 		} else {
-			zEevent := l.logEvent().
-				Str("name", e.Name).
-				Str("kind", e.Kind).
-				Str("runtime", e.Runtime.String())
-			maybeStringField(zEevent, "module", e.ModuleName).
+			zEevent := l.timedLogEvent(event, info, e.Runtime).
+				EmbedObject(l.functionField(f.Name, e.Name)).
+				Str(f.Kind, e.Kind).
+				EmbedObject(l.runtimeField(e.Runtime))
+			maybeStringField(zEevent, f.Module, e.ModuleName).
 				Msg("run")
 		}
 	case *fxevent.Invoking:
 		// Do not log stack as it will make logs hard to read.
-		zEvent := l.logEvent().
-			Str("function", e.FunctionName)
-		maybeStringField(zEvent, "module", e.ModuleName).
+		zEvent := l.logEvent(event, info).
+			EmbedObject(l.functionField(f.Function, e.FunctionName))
+		maybeStringField(zEvent, f.Module, e.ModuleName).
 			Msg("invoking")
 	case *fxevent.Invoked:
 		if e.Err != nil {
-			zEvent := l.errorLogEvent().
-				Err(e.Err).
-				Str("stack", e.Trace).
-				Str("function", e.FunctionName)
-			maybeStringField(zEvent, "module", e.ModuleName).
-				Msg("invoke failed")
+			zEvent := l.errorLogEvent(event, info).
+				EmbedObject(l.errorField(e.Err)).
+				EmbedObject(l.traceField(f.Stack, e.Trace)).
+				EmbedObject(l.functionField(f.Function, e.FunctionName))
+			maybeStringField(zEvent, f.Module, e.ModuleName)
+			if info.timed {
+				zEvent.EmbedObject(l.runtimeField(info.runtime))
+			}
+			zEvent.Msg("invoke failed")
+		} else if l.logInvoked {
+			zEvent := l.logEvent(event, info).
+				EmbedObject(l.functionField(f.Function, e.FunctionName))
+			maybeStringField(zEvent, f.Module, e.ModuleName)
+			if info.timed {
+				zEvent.EmbedObject(l.runtimeField(info.runtime))
+			}
+			zEvent.Msg("invoked")
 		}
 	case *fxevent.Stopping:
-		l.logEvent().
-			Str("signal", strings.ToUpper(e.Signal.String())).
+		l.logEvent(event, info).
+			Str(f.Signal, strings.ToUpper(e.Signal.String())).
 			Msg("received signal")
 	case *fxevent.Stopped:
 		if e.Err != nil {
-			l.errorLogEvent().
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("stop failed")
 		}
 	case *fxevent.RollingBack:
-		l.errorLogEvent().
-			Err(e.StartErr).
+		l.errorLogEvent(event, info).
+			EmbedObject(l.errorField(e.StartErr)).
 			Msg("start failed, rolling back")
 	case *fxevent.RolledBack:
 		if e.Err != nil {
-			l.errorLogEvent().
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("rollback failed")
 		}
 	case *fxevent.Started:
 		if e.Err != nil {
-			l.errorLogEvent().
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("start failed")
 		} else {
-			l.logEvent().
+			l.logEvent(event, info).
 				Msg("started")
 		}
 	case *fxevent.LoggerInitialized:
 		if e.Err != nil {
-			l.errorLogEvent().
-				Err(e.Err).
+			l.errorLogEvent(event, info).
+				EmbedObject(l.errorField(e.Err)).
 				Msg("custom logger initialization failed")
 		} else {
-			l.logEvent().
-				Str("function", e.ConstructorName).
+			l.logEvent(event, info).
+				EmbedObject(l.functionField(f.Function, e.ConstructorName)).
 				Msg("initialized custom fxevent.Logger")
 		}
 	}
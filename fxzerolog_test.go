@@ -498,6 +498,35 @@ func TestZerologLogger(t *testing.T) {
 		}
 	})
 
+	t.Run("trace observer, per event type levels", func(t *testing.T) {
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+				New(core,
+					WithLogLevel(zerolog.InfoLevel),
+					WithErrorLevel(zerolog.ErrorLevel),
+					WithEventLevel(tt.give, zerolog.WarnLevel),
+					WithEventErrorLevel(tt.give, zerolog.FatalLevel),
+				).LogEvent(tt.give)
+
+				logs := observedLogs.TakeAll()
+				require.Len(t, logs, 1)
+				got := logs[0]
+
+				if strings.HasSuffix(tt.name, "/Error") {
+					assert.Equal(t, "fatal", got.Level())
+				} else {
+					assert.Equal(t, "warn", got.Level())
+				}
+				assert.Equal(t, tt.wantMessage, got.Message())
+				assert.Equal(t, tt.wantFields, got.Fields())
+			})
+		}
+	})
+
 	t.Run("test setting log levels", func(t *testing.T) {
 		levels := []zerolog.Level{
 			zerolog.ErrorLevel,
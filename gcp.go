@@ -0,0 +1,206 @@
+package fxzerolog
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx/fxevent"
+)
+
+const (
+	gcpSeverityKey       = "severity"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpTypeKey           = "@type"
+	gcpContextKey        = "context"
+	gcpServiceContextKey = "serviceContext"
+	gcpStackTraceKey     = "stack_trace"
+
+	gcpReportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+)
+
+// GCPSeverityHook is a zerolog.Hook adding the severity expected by Google
+// Cloud Logging to every line, e.g. "WARNING" for zerolog.WarnLevel.
+type GCPSeverityHook struct{}
+
+// Run implements zerolog.Hook.
+func (GCPSeverityHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	e.Str(gcpSeverityKey, gcpSeverity(level))
+}
+
+func gcpSeverity(level zerolog.Level) string {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return "DEBUG"
+	case zerolog.InfoLevel:
+		return "INFO"
+	case zerolog.WarnLevel:
+		return "WARNING"
+	case zerolog.ErrorLevel:
+		return "ERROR"
+	case zerolog.FatalLevel:
+		return "CRITICAL"
+	case zerolog.PanicLevel:
+		return "ALERT"
+	}
+
+	return "DEFAULT"
+}
+
+// WithGCP makes the logger emit lines understood by Google Cloud Logging:
+//
+//   - the severity, through GCPSeverityHook,
+//   - logging.googleapis.com/sourceLocation derived from the first frame of
+//     the stack trace of the event, when it has one,
+//   - an Error Reporting payload for the error lines that can be located: a
+//     stack_trace with the error, and the stack formatted as a Go panic
+//     when the event has one, a context.reportLocation and a serviceContext
+//     when WithService is used. Failed hooks, and the rollback of a failed
+//     OnStart hook, are reported from the function of the hook. Failures
+//     that cannot be located, such as a failed fxevent.Started, are left to
+//     Cloud Logging.
+//
+// The logger given to New must not already have GCPSeverityHook. Module
+// installs the hook on the application logger as well.
+func WithGCP() Option {
+	return func(l *ZerologLogger) {
+		if !l.gcp {
+			l.Logger = l.Logger.Hook(GCPSeverityHook{})
+		}
+		l.gcp = true
+	}
+}
+
+func (l *ZerologLogger) gcpFields(zEvent *zerolog.Event, event fxevent.Event, info eventInfo, failed bool) {
+	frames := eventFrames(event)
+	if len(frames) > 0 && len(frames[0].file) > 0 {
+		zEvent.Dict(gcpSourceLocationKey, zerolog.Dict().
+			Str("file", frames[0].file).
+			Str("line", strconv.Itoa(frames[0].line)).
+			Str("function", frames[0].function))
+	}
+
+	err := eventErr(event)
+	if !failed || err == nil {
+		return
+	}
+
+	// Error Reporting needs either a stack trace or a location to report.
+	location := frame{function: gcpReportFunction(event, info)}
+	stackTrace := err.Error()
+	if len(frames) > 0 && len(frames[0].file) > 0 && frames[0].line > 0 {
+		location = frames[0]
+		stackTrace = gcpStackTrace(err, frames)
+	}
+	if len(location.function) == 0 {
+		return
+	}
+
+	zEvent.Str(gcpTypeKey, gcpReportedErrorEventType).
+		Str(gcpStackTraceKey, stackTrace)
+	if len(l.service) > 0 {
+		zEvent.Dict(gcpServiceContextKey, zerolog.Dict().
+			Str("service", l.service))
+	}
+	reportLocation := zerolog.Dict()
+	if len(location.file) > 0 {
+		reportLocation.
+			Str("filePath", location.file).
+			Int("lineNumber", location.line)
+	}
+	zEvent.Dict(gcpContextKey, zerolog.Dict().
+		Dict("reportLocation", reportLocation.
+			Str("functionName", location.function)))
+}
+
+// gcpReportFunction returns the function to report the failure of event
+// from, when it has no stack trace: the hook that failed, or the function
+// invoked.
+func gcpReportFunction(event fxevent.Event, info eventInfo) string {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuted:
+		return hookFunction(e.FunctionName, e.CallerName)
+	case *fxevent.OnStopExecuted:
+		return hookFunction(e.FunctionName, e.CallerName)
+	case *fxevent.RollingBack:
+		return info.failedHook
+	case *fxevent.Invoked:
+		return e.FunctionName
+	}
+
+	return ""
+}
+
+// hookFunction returns the function of a hook, or the function that
+// registered it when the hook is anonymous.
+func hookFunction(callee, caller string) string {
+	if len(callee) > 0 {
+		return callee
+	}
+
+	return caller
+}
+
+// observeFailedHook records the OnStart hook that failed the start, and
+// returns it for the rollback that follows. It must be called with l.mu
+// held.
+func (l *ZerologLogger) observeFailedHook(event fxevent.Event) string {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuted:
+		if e.Err != nil {
+			l.failedHook = hookFunction(e.FunctionName, e.CallerName)
+		}
+	case *fxevent.RollingBack:
+		failedHook := l.failedHook
+		l.failedHook = ""
+		return failedHook
+	}
+
+	return ""
+}
+
+// gcpStackTrace formats err and frames as a Go panic, which Error Reporting
+// groups by the error and the frames.
+func gcpStackTrace(err error, frames []frame) string {
+	var sb strings.Builder
+	sb.WriteString(err.Error())
+	sb.WriteString("\n\ngoroutine 1 [running]:\n")
+	for _, f := range frames {
+		if len(f.file) == 0 {
+			continue
+		}
+		sb.WriteString(f.function)
+		sb.WriteString("\n\t")
+		sb.WriteString(f.file)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(f.line))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// eventFrames returns the stack of the code an event originates from,
+// innermost frame first.
+func eventFrames(event fxevent.Event) []frame {
+	var stackTrace []string
+	switch e := event.(type) {
+	case *fxevent.Supplied:
+		stackTrace = e.StackTrace
+	case *fxevent.Provided:
+		stackTrace = e.StackTrace
+	case *fxevent.Replaced:
+		stackTrace = e.StackTrace
+	case *fxevent.Decorated:
+		stackTrace = e.StackTrace
+	case *fxevent.Invoked:
+		return parseTrace(e.Trace)
+	}
+
+	frames := make([]frame, len(stackTrace))
+	for i, s := range stackTrace {
+		frames[i] = parseFrame(s)
+	}
+
+	return frames
+}
@@ -0,0 +1,152 @@
+package fxzerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestGCPSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := map[zerolog.Level]string{
+		zerolog.TraceLevel: "DEBUG",
+		zerolog.DebugLevel: "DEBUG",
+		zerolog.InfoLevel:  "INFO",
+		zerolog.WarnLevel:  "WARNING",
+		zerolog.ErrorLevel: "ERROR",
+		zerolog.FatalLevel: "CRITICAL",
+		zerolog.PanicLevel: "ALERT",
+		zerolog.NoLevel:    "DEFAULT",
+	}
+
+	for level, want := range tests {
+		assert.Equal(t, want, gcpSeverity(level), level.String())
+	}
+}
+
+func TestWithGCP(t *testing.T) {
+	t.Run("source location", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithGCP())
+		l.LogEvent(&fxevent.Provided{
+			ConstructorName: "bytes.NewBuffer()",
+			StackTrace:      []string{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+			OutputTypeNames: []string{"*bytes.Buffer"},
+		})
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "DEBUG", logs[0].Fields()["severity"])
+		assert.Equal(t, map[string]any{
+			"file":     "/app/main.go",
+			"line":     "12",
+			"function": "main.main",
+		}, logs[0].Fields()["logging.googleapis.com/sourceLocation"])
+		assert.NotContains(t, logs[0].Fields(), "@type")
+		assert.Equal(t, map[string]any{"severity": "DEBUG"}, logs[1].Fields())
+	})
+
+	t.Run("error reporting", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithGCP(), WithService("fx"))
+		l.LogEvent(&fxevent.Invoked{
+			FunctionName: "main.run()",
+			Err:          errors.New("some error"),
+			Trace:        "main.main()\n\t/app/main.go:12\nruntime.main()\n\t/usr/lib/go/src/runtime/proc.go:272\n",
+		})
+		l.LogEvent(&fxevent.Provided{
+			StackTrace: []string{"main.main (/app/main.go:10)"},
+			Err:        errors.New("other error"),
+		})
+		l.LogEvent(&fxevent.OnStartExecuted{
+			FunctionName: "main.onStart",
+			CallerName:   "main.main",
+			Err:          errors.New("some error"),
+		})
+		l.LogEvent(&fxevent.RollingBack{StartErr: errors.New("some error")})
+		l.LogEvent(&fxevent.Started{Err: errors.New("some error")})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 5)
+		for _, entry := range logs {
+			assert.Equal(t, "ERROR", entry.Fields()["severity"])
+		}
+
+		for _, entry := range logs[:2] {
+			assert.Equal(t, gcpReportedErrorEventType, entry.Fields()["@type"])
+			assert.Equal(t, map[string]any{"service": "fx"}, entry.Fields()["serviceContext"])
+			assert.Contains(t, entry.Fields(), "logging.googleapis.com/sourceLocation")
+		}
+		assert.Equal(t, map[string]any{
+			"reportLocation": map[string]any{
+				"filePath":     "/app/main.go",
+				"lineNumber":   float64(12),
+				"functionName": "main.main()",
+			},
+		}, logs[0].Fields()["context"])
+		assert.Equal(t, "some error\n\ngoroutine 1 [running]:\n"+
+			"main.main()\n\t/app/main.go:12\n"+
+			"runtime.main()\n\t/usr/lib/go/src/runtime/proc.go:272\n",
+			logs[0].Fields()["stack_trace"])
+		assert.Equal(t, "other error\n\ngoroutine 1 [running]:\nmain.main\n\t/app/main.go:10\n",
+			logs[1].Fields()["stack_trace"])
+
+		// Failed hooks, and the rollback they cause, are reported from the
+		// function of the hook.
+		for _, entry := range logs[2:4] {
+			assert.Equal(t, gcpReportedErrorEventType, entry.Fields()["@type"], entry.Message())
+			assert.Equal(t, map[string]any{"service": "fx"}, entry.Fields()["serviceContext"])
+			assert.Equal(t, map[string]any{
+				"reportLocation": map[string]any{"functionName": "main.onStart"},
+			}, entry.Fields()["context"])
+			assert.Equal(t, "some error", entry.Fields()["stack_trace"])
+		}
+
+		// Failures without a location are not reported to Error Reporting.
+		started := logs[4]
+		assert.NotContains(t, started.Fields(), "@type")
+		assert.NotContains(t, started.Fields(), "context")
+		assert.NotContains(t, started.Fields(), "serviceContext")
+
+		l.LogEvent(&fxevent.RollingBack{StartErr: errors.New("some error")})
+		logs = observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.NotContains(t, logs[0].Fields(), "@type", "a rollback without a failed hook cannot be located")
+	})
+
+	t.Run("module", func(t *testing.T) {
+		_, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+
+		var logger zerolog.Logger
+		app := fxtest.New(t,
+			fx.Supply(Config{Level: "debug", Output: observedLogs}),
+			Module(WithGCP()),
+			fx.Populate(&logger),
+		)
+		app.RequireStart().RequireStop()
+		logger.Warn().Msg("hello")
+
+		logs := observedLogs.TakeAll()
+		require.NotEmpty(t, logs)
+		for _, entry := range logs {
+			assert.Contains(t, entry.Fields(), "severity")
+		}
+		last := logs[len(logs)-1]
+		assert.Equal(t, "hello", last.Message())
+		assert.Equal(t, "WARNING", last.Fields()["severity"])
+
+		// Each line must carry the severity exactly once.
+		for _, entry := range logs {
+			assert.Equal(t, 1, bytes.Count(entry.record, []byte(`"severity":`)), string(entry.record))
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package fxzerolog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// WithHookIDs adds a hook_id field to the lines of OnStart hooks, OnStop
+// hooks and invokes, pairing their executing and executed events. IDs
+// increase monotonically with the executing events. When several hooks of a
+// function are registered by the same caller, or several invokes of a
+// function by the same module, they are paired in order.
+func WithHookIDs() Option {
+	return func(l *ZerologLogger) {
+		l.hookIDs = &hookIDs{}
+	}
+}
+
+// WithRunID adds a run_id field with id to every line. An empty id is
+// replaced with a random one, see RunID.
+func WithRunID(id string) Option {
+	return func(l *ZerologLogger) {
+		if len(id) == 0 {
+			id = newRunID()
+		}
+		l.runID = id
+	}
+}
+
+// RunID returns the ID added to every line by WithRunID, if any.
+func (l *ZerologLogger) RunID() string {
+	return l.runID
+}
+
+func newRunID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// hookIDs assigns IDs to the hooks and invokes.
+type hookIDs struct {
+	last    uint64
+	pending pending[uint64]
+}
+
+// observe returns the ID of event, or zero if it has none.
+func (h *hookIDs) observe(event fxevent.Event) uint64 {
+	key, executing, ok := pairKeyOf(event)
+	if !ok {
+		return 0
+	}
+
+	if executing {
+		h.last++
+		h.pending.push(key, h.last)
+		return h.last
+	}
+
+	id, _ := h.pending.pop(key)
+	return id
+}
+
+// pairKey identifies the executing events an executed event may pair with.
+type pairKey struct {
+	executing reflect.Type
+	function  string
+	caller    string
+}
+
+// pairKeyOf returns the key pairing the executing and executed events of
+// hooks and invokes, and whether event is an executing one.
+func pairKeyOf(event fxevent.Event) (key pairKey, executing, ok bool) {
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuting:
+		return pairKey{reflect.TypeOf(e), e.FunctionName, e.CallerName}, true, true
+	case *fxevent.OnStopExecuting:
+		return pairKey{reflect.TypeOf(e), e.FunctionName, e.CallerName}, true, true
+	case *fxevent.Invoking:
+		return pairKey{reflect.TypeOf(e), e.FunctionName, e.ModuleName}, true, true
+	case *fxevent.OnStartExecuted:
+		return pairKey{reflect.TypeOf((*fxevent.OnStartExecuting)(nil)), e.FunctionName, e.CallerName}, false, true
+	case *fxevent.OnStopExecuted:
+		return pairKey{reflect.TypeOf((*fxevent.OnStopExecuting)(nil)), e.FunctionName, e.CallerName}, false, true
+	case *fxevent.Invoked:
+		return pairKey{reflect.TypeOf((*fxevent.Invoking)(nil)), e.FunctionName, e.ModuleName}, false, true
+	}
+
+	return pairKey{}, false, false
+}
+
+// pending holds values of executing events until their executed event, in
+// order for each key.
+type pending[T any] map[pairKey][]T
+
+func (p *pending[T]) push(key pairKey, v T) {
+	if *p == nil {
+		*p = make(pending[T])
+	}
+
+	(*p)[key] = append((*p)[key], v)
+}
+
+func (p pending[T]) pop(key pairKey) (T, bool) {
+	values := p[key]
+	if len(values) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	if len(values) == 1 {
+		delete(p, key)
+	} else {
+		p[key] = values[1:]
+	}
+
+	return values[0], true
+}
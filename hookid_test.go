@@ -0,0 +1,87 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestWithHookIDs(t *testing.T) {
+	core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+	l := New(core, WithHookIDs())
+
+	someError := errors.New("some error")
+	events := []fxevent.Event{
+		&fxevent.Invoking{FunctionName: "main.run", ModuleName: "app"},
+		&fxevent.Invoking{FunctionName: "main.run", ModuleName: "other"},
+		&fxevent.Invoked{FunctionName: "main.run", ModuleName: "other", Err: someError},
+		&fxevent.Invoked{FunctionName: "main.run", ModuleName: "app", Err: someError},
+		&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.newA"},
+		&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.newB"},
+		&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.newA"},
+		&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.newB"},
+		&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.newA"},
+		&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.newA", Err: someError},
+		&fxevent.Started{},
+		&fxevent.OnStopExecuting{FunctionName: "hook.onStart", CallerName: "main.newA"},
+		&fxevent.OnStopExecuted{FunctionName: "hook.onStart", CallerName: "main.newA"},
+		&fxevent.OnStopExecuted{FunctionName: "hook.onStop", CallerName: "main.newA"},
+	}
+	for _, event := range events {
+		l.LogEvent(event)
+	}
+
+	var ids []any
+	for _, log := range observedLogs.TakeAll() {
+		ids = append(ids, log.Fields()["hook_id"])
+	}
+	assert.Equal(t, []any{
+		float64(1), float64(2), float64(2), float64(1),
+		float64(3), float64(4), float64(5), float64(4), float64(3), float64(5),
+		nil,
+		float64(6), float64(6), nil,
+	}, ids)
+}
+
+func TestWithRunID(t *testing.T) {
+	t.Run("given", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithRunID("some-run"))
+		l.LogEvent(&fxevent.Started{})
+		l.LogEvent(&fxevent.Stopped{Err: errors.New("some error")})
+
+		assert.Equal(t, "some-run", l.RunID())
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		for _, log := range logs {
+			assert.Equal(t, "some-run", log.Fields()["run_id"])
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithRunID(""))
+		l.LogEvent(&fxevent.Started{})
+
+		assert.Len(t, l.RunID(), 16)
+		assert.NotEqual(t, l.RunID(), New(core, WithRunID("")).RunID())
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, l.RunID(), logs[0].Fields()["run_id"])
+	})
+
+	t.Run("none", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core)
+		l.LogEvent(&fxevent.Started{})
+
+		assert.Empty(t, l.RunID())
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.NotContains(t, logs[0].Fields(), "run_id")
+	})
+}
@@ -0,0 +1,32 @@
+package fxzerolog
+
+import (
+	"time"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// WithInvokedLogging logs an "invoked" line, with its runtime, for every
+// successful invoke. Only failed invokes are logged by default.
+func WithInvokedLogging() Option {
+	return func(l *ZerologLogger) {
+		l.logInvoked = true
+	}
+}
+
+// timeInvoke records when invokes start and returns the runtime of event if
+// it is the end of one. It must be called with l.mu held.
+func (l *ZerologLogger) timeInvoke(event fxevent.Event, now time.Time) (time.Duration, bool) {
+	switch event.(type) {
+	case *fxevent.Invoking:
+		key, _, _ := pairKeyOf(event)
+		l.invokes.push(key, now)
+	case *fxevent.Invoked:
+		key, _, _ := pairKeyOf(event)
+		if start, ok := l.invokes.pop(key); ok {
+			return now.Sub(start), true
+		}
+	}
+
+	return 0, false
+}
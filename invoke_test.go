@@ -0,0 +1,59 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestInvokeRuntime(t *testing.T) {
+	events := []fxevent.Event{
+		&fxevent.Invoking{FunctionName: "main.run()", ModuleName: "myModule"},
+		&fxevent.Invoking{FunctionName: "main.serve()"},
+		&fxevent.Invoked{FunctionName: "main.serve()"},
+		&fxevent.Invoked{FunctionName: "main.run()", ModuleName: "myModule", Err: errors.New("some error")},
+		&fxevent.Invoked{FunctionName: "main.unknown()"},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithDurationUnit(time.Millisecond))
+		l.now = fakeClock(time.Millisecond)
+		for _, event := range events {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 3)
+		assert.Equal(t, "invoke failed", logs[2].Message())
+		assert.Equal(t, float64(3), logs[2].Fields()["runtime"])
+		assert.Empty(t, l.invokes)
+	})
+
+	t.Run("invoked logging", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithDurationUnit(time.Millisecond), WithInvokedLogging())
+		l.now = fakeClock(time.Millisecond)
+		for _, event := range events {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 5)
+		assert.Equal(t, "invoked", logs[2].Message())
+		assert.Equal(t, "debug", logs[2].Level())
+		assert.Equal(t, map[string]any{
+			"function": "main.serve()",
+			"runtime":  float64(1),
+		}, logs[2].Fields())
+		assert.Equal(t, "invoke failed", logs[3].Message())
+		assert.Equal(t, float64(3), logs[3].Fields()["runtime"])
+		assert.Equal(t, "invoked", logs[4].Message())
+		assert.NotContains(t, logs[4].Fields(), "runtime", "unpaired invokes are not timed")
+	})
+}
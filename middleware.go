@@ -0,0 +1,62 @@
+package fxzerolog
+
+import (
+	"github.com/rs/zerolog"
+	"go.uber.org/fx/fxevent"
+)
+
+// LineHandler handles a line about to be logged. It returns the line to log,
+// or nil to drop it.
+type LineHandler func(zEvent *zerolog.Event) *zerolog.Event
+
+// EventHandler handles a line about to be logged for event. zEvent is nil
+// when the level of the line is disabled. It returns the line to log, which
+// may be zEvent with more fields, another line, e.g. started from the logger
+// given to the Middleware at another level, or nil to drop the line.
+//
+// Events cannot be rewritten: event tells what the line is about, and the
+// next handler only receives the line.
+type EventHandler func(event fxevent.Event, zEvent *zerolog.Event) *zerolog.Event
+
+// Middleware wraps the handling of the lines logged by a ZerologLogger. It is
+// called for every line with the logger the line is logged to, including its
+// hooks such as GCPSeverityHook, and the next handler of the chain.
+type Middleware func(logger *zerolog.Logger, next LineHandler) EventHandler
+
+// WithMiddleware adds middleware to the logger, see ZerologLogger.Use.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(l *ZerologLogger) {
+		l.Use(middleware...)
+	}
+}
+
+// Use adds middleware handling every line logged by l before it is encoded:
+// they are called with the event and the line, before the fields of the
+// event are added, so that they can add fields, change the level, or drop
+// the line. Middleware added first is called first.
+func (l *ZerologLogger) Use(middleware ...Middleware) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.middleware = append(l.middleware, middleware...)
+
+	chain := append([]Middleware(nil), l.middleware...)
+	l.chain.Store(&chain)
+}
+
+// handle passes zEvent, a line about to be logged for event, through the
+// middleware.
+func (l *ZerologLogger) handle(middleware []Middleware, event fxevent.Event, zEvent *zerolog.Event) *zerolog.Event {
+	logger := l.Logger
+	next := LineHandler(func(zEvent *zerolog.Event) *zerolog.Event {
+		return zEvent
+	})
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler := middleware[i](&logger, next)
+		next = func(zEvent *zerolog.Event) *zerolog.Event {
+			return handler(event, zEvent)
+		}
+	}
+
+	return next(zEvent)
+}
@@ -0,0 +1,78 @@
+package fxzerolog
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestUse(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		owners := map[string]string{"billing": "team-billing"}
+
+		var calls []string
+		trace := func(name string) Middleware {
+			return func(_ *zerolog.Logger, next LineHandler) EventHandler {
+				return func(_ fxevent.Event, zEvent *zerolog.Event) *zerolog.Event {
+					calls = append(calls, name)
+					return next(zEvent)
+				}
+			}
+		}
+		owner := func(_ *zerolog.Logger, next LineHandler) EventHandler {
+			return func(event fxevent.Event, zEvent *zerolog.Event) *zerolog.Event {
+				if module, ok := eventModule(event); ok && len(owners[module]) > 0 {
+					zEvent = zEvent.Str("owner", owners[module])
+				}
+				return next(zEvent)
+			}
+		}
+
+		l := New(core, WithMiddleware(trace("first"), owner))
+		l.Use(trace("last"))
+
+		l.LogEvent(&fxevent.Invoking{FunctionName: "main.run", ModuleName: "billing"})
+		l.LogEvent(&fxevent.Invoking{FunctionName: "main.run", ModuleName: "other"})
+
+		assert.Equal(t, []string{"first", "last", "first", "last"}, calls)
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, map[string]any{
+			"owner":    "team-billing",
+			"function": "main.run",
+			"module":   "billing",
+		}, logs[0].Fields())
+		assert.NotContains(t, logs[1].Fields(), "owner")
+	})
+
+	t.Run("level and drop", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core.Level(zerolog.InfoLevel), WithLogLevel(zerolog.TraceLevel), WithService("fx"), WithGCP())
+		l.Use(func(logger *zerolog.Logger, next LineHandler) EventHandler {
+			return func(event fxevent.Event, zEvent *zerolog.Event) *zerolog.Event {
+				switch event.(type) {
+				case *fxevent.Started:
+					assert.Nil(t, zEvent, "trace lines should be disabled")
+					zEvent = logger.Warn()
+				case *fxevent.Invoking:
+					return nil
+				}
+				return next(zEvent)
+			}
+		})
+
+		l.LogEvent(&fxevent.Invoking{FunctionName: "main.run"})
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, "warn", logs[0].Level())
+		assert.Equal(t, "started", logs[0].Message())
+		assert.Equal(t, map[string]any{"service": "fx", "severity": "WARNING"}, logs[0].Fields(),
+			"the hooks of the logger should apply")
+	})
+}
@@ -0,0 +1,100 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// Config configures the application logger provided by Module.
+//
+// Supply it to the application, e.g. with fx.Supply, to override the defaults.
+type Config struct {
+	// Level is the minimum level of the application logger, such as "debug"
+	// or "warn". Defaults to "info".
+	Level string
+
+	// Output is where the application logger writes to. Defaults to os.Stderr.
+	Output io.Writer
+
+	// Console switches the output to zerolog's human-friendly console format.
+	Console bool
+}
+
+type moduleParams struct {
+	fx.In
+
+	Config Config `optional:"true"`
+}
+
+type moduleResult struct {
+	fx.Out
+
+	Logger        zerolog.Logger
+	LoggerPointer *zerolog.Logger
+	EventLogger   *ZerologLogger
+}
+
+// Module returns an Fx option that provides the application zerolog.Logger,
+// in both value and pointer forms, built from an optional Config, and
+// installs a ZerologLogger configured by opts as the Fx event logger.
+//
+// Fx events are logged through the application logger tagged with
+// service=fx, see WithService. The ZerologLogger itself is provided as well.
+func Module(opts ...Option) fx.Option {
+	return fx.Options(
+		fx.Provide(func(p moduleParams) (moduleResult, error) {
+			return newModuleResult(p.Config, opts)
+		}),
+		fx.WithLogger(func(l *ZerologLogger) fxevent.Logger {
+			return l
+		}),
+	)
+}
+
+func newModuleResult(config Config, opts []Option) (moduleResult, error) {
+	logger, err := newApplicationLogger(config)
+	if err != nil {
+		return moduleResult{}, err
+	}
+
+	eventLogger, err := newLogger(logger, append([]Option{WithService("fx")}, opts...))
+	if err != nil {
+		return moduleResult{}, err
+	}
+
+	if eventLogger.gcp {
+		logger = logger.Hook(GCPSeverityHook{})
+	}
+
+	return moduleResult{
+		Logger:        logger,
+		LoggerPointer: &logger,
+		EventLogger:   eventLogger,
+	}, nil
+}
+
+func newApplicationLogger(config Config) (zerolog.Logger, error) {
+	level := zerolog.InfoLevel
+	if len(config.Level) > 0 {
+		var err error
+		if level, err = zerolog.ParseLevel(config.Level); err != nil {
+			return zerolog.Logger{}, fmt.Errorf("fxzerolog: invalid level %q: %w", config.Level, err)
+		}
+	}
+
+	output := config.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	if config.Console {
+		output = zerolog.ConsoleWriter{Out: output}
+	}
+
+	return zerolog.New(output).Level(level).With().Timestamp().Logger(), nil
+}
@@ -0,0 +1,92 @@
+package fxzerolog
+
+import (
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModule(t *testing.T) {
+	t.Run("provides loggers", func(t *testing.T) {
+		_, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+
+		var (
+			logger        zerolog.Logger
+			loggerPointer *zerolog.Logger
+			eventLogger   *ZerologLogger
+		)
+		app := fxtest.New(t,
+			fx.Supply(Config{Level: "debug", Output: observedLogs}),
+			Module(),
+			fx.Populate(&logger, &loggerPointer, &eventLogger),
+		)
+		app.RequireStart().RequireStop()
+
+		require.NotNil(t, loggerPointer)
+		require.NotNil(t, eventLogger)
+		assert.Equal(t, zerolog.DebugLevel, logger.GetLevel())
+
+		logs := observedLogs.TakeAll()
+		require.NotEmpty(t, logs)
+		for _, entry := range logs {
+			assert.Equal(t, "fx", entry.Fields()["service"])
+			assert.Contains(t, entry.Fields(), zerolog.TimestampFieldName)
+		}
+		assert.Equal(t, "started", logs[len(logs)-1].Message())
+	})
+
+	t.Run("defaults to info", func(t *testing.T) {
+		_, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+
+		var logger zerolog.Logger
+		app := fxtest.New(t,
+			fx.Supply(Config{Output: observedLogs}),
+			Module(),
+			fx.Populate(&logger),
+		)
+		app.RequireStart().RequireStop()
+
+		assert.Equal(t, zerolog.InfoLevel, logger.GetLevel())
+		assert.Empty(t, observedLogs.TakeAll(), "debug Fx events should be hidden")
+	})
+
+	t.Run("event logger options", func(t *testing.T) {
+		_, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+
+		app := fxtest.New(t,
+			fx.Supply(Config{Output: observedLogs}),
+			Module(WithLogLevel(zerolog.InfoLevel)),
+		)
+		app.RequireStart().RequireStop()
+
+		logs := observedLogs.TakeAll()
+		require.NotEmpty(t, logs)
+		for _, entry := range logs {
+			assert.Equal(t, "info", entry.Level())
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		app := fx.New(
+			fx.Supply(Config{Level: "verbose"}),
+			Module(),
+			fx.Invoke(func(zerolog.Logger) {}),
+		)
+		require.Error(t, app.Err())
+		assert.Contains(t, app.Err().Error(), `fxzerolog: invalid level "verbose"`)
+	})
+	t.Run("invalid option", func(t *testing.T) {
+		app := fx.New(
+			fx.Supply(Config{Output: io.Discard}),
+			Module(WithMaxFrames(-1)),
+			fx.Invoke(func(zerolog.Logger) {}),
+		)
+		require.Error(t, app.Err())
+		assert.Contains(t, app.Err().Error(), "fxzerolog: invalid max frames -1")
+	})
+}
@@ -0,0 +1,165 @@
+package fxzerolog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx/fxevent"
+)
+
+// Option configures a ZerologLogger created by New.
+type Option func(*ZerologLogger)
+
+// New returns a ZerologLogger that logs Fx events to logger, configured by opts.
+//
+// Options are applied in order, so later options override earlier ones.
+// New panics if the resulting configuration is invalid, such as a level
+// outside of the range defined by zerolog.
+func New(logger zerolog.Logger, opts ...Option) *ZerologLogger {
+	l, err := newLogger(logger, opts)
+	if err != nil {
+		panic(err)
+	}
+
+	return l
+}
+
+// newLogger is New returning an invalid configuration as an error.
+func newLogger(logger zerolog.Logger, opts []Option) (*ZerologLogger, error) {
+	l := &ZerologLogger{Logger: logger}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// WithLogLevel sets the level of non-error logs emitted by Fx to level.
+// Defaults to zerolog.DebugLevel.
+func WithLogLevel(level zerolog.Level) Option {
+	return func(l *ZerologLogger) {
+		l.logLevel = level
+	}
+}
+
+// WithErrorLevel sets the level of error logs emitted by Fx to level.
+// Defaults to zerolog.ErrorLevel.
+func WithErrorLevel(level zerolog.Level) Option {
+	return func(l *ZerologLogger) {
+		l.errorLevel = &level
+	}
+}
+
+// WithService adds the name of the service to every line, under the
+// FieldNames.Service key.
+func WithService(name string) Option {
+	return func(l *ZerologLogger) {
+		l.service = name
+	}
+}
+
+// WithEventLevel sets the level of non-error logs emitted for events of the
+// same type as event, overriding WithLogLevel for them. The event is only
+// used for its type, so a typed nil such as (*fxevent.Provided)(nil) works.
+func WithEventLevel(event fxevent.Event, level zerolog.Level) Option {
+	return func(l *ZerologLogger) {
+		if l.eventLevels == nil {
+			l.eventLevels = make(map[reflect.Type]zerolog.Level)
+		}
+		l.eventLevels[reflect.TypeOf(event)] = level
+	}
+}
+
+// WithEventErrorLevel sets the level of error logs emitted for events of the
+// same type as event, overriding WithErrorLevel for them. The event is only
+// used for its type, so a typed nil such as (*fxevent.Invoked)(nil) works.
+func WithEventErrorLevel(event fxevent.Event, level zerolog.Level) Option {
+	return func(l *ZerologLogger) {
+		if l.eventErrorLevels == nil {
+			l.eventErrorLevels = make(map[reflect.Type]zerolog.Level)
+		}
+		l.eventErrorLevels[reflect.TypeOf(event)] = level
+	}
+}
+
+func (l *ZerologLogger) validate() error {
+	if !validLevel(l.logLevel) {
+		return fmt.Errorf("fxzerolog: invalid log level %d", l.logLevel)
+	}
+
+	if l.errorLevel != nil && !validLevel(*l.errorLevel) {
+		return fmt.Errorf("fxzerolog: invalid error level %d", *l.errorLevel)
+	}
+
+	if l.stacks.maxFrames < 0 {
+		return fmt.Errorf("fxzerolog: invalid max frames %d", l.stacks.maxFrames)
+	}
+
+	if err := l.durations.validate(); err != nil {
+		return err
+	}
+
+	if err := l.slow.validate(); err != nil {
+		return err
+	}
+
+	if l.recent != nil {
+		if err := l.recent.validate(); err != nil {
+			return err
+		}
+	}
+
+	if l.deferred != nil {
+		if err := l.deferred.validate(); err != nil {
+			return err
+		}
+	}
+
+	if l.watchdog != nil {
+		if err := l.watchdog.validate(); err != nil {
+			return err
+		}
+	}
+
+	if l.startup != nil {
+		if err := l.startup.validate(); err != nil {
+			return err
+		}
+	}
+
+	if l.shutdown != nil {
+		if err := l.shutdown.validate(); err != nil {
+			return err
+		}
+	}
+
+	for typ, level := range l.eventLevels {
+		if typ == nil {
+			return errors.New("fxzerolog: nil event type")
+		}
+		if !validLevel(level) {
+			return fmt.Errorf("fxzerolog: invalid log level %d for %v", level, typ)
+		}
+	}
+
+	for typ, level := range l.eventErrorLevels {
+		if typ == nil {
+			return errors.New("fxzerolog: nil event type")
+		}
+		if !validLevel(level) {
+			return fmt.Errorf("fxzerolog: invalid error level %d for %v", level, typ)
+		}
+	}
+
+	return nil
+}
+
+func validLevel(level zerolog.Level) bool {
+	return level >= zerolog.TraceLevel && level <= zerolog.Disabled
+}
@@ -0,0 +1,90 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core)
+		l.LogEvent(&fxevent.Started{})
+		l.LogEvent(&fxevent.Started{Err: errors.New("some error")})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "debug", logs[0].Level())
+		assert.Equal(t, "error", logs[1].Level())
+	})
+
+	t.Run("with levels", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithLogLevel(zerolog.InfoLevel),
+			WithErrorLevel(zerolog.WarnLevel),
+		)
+		l.LogEvent(&fxevent.Started{})
+		l.LogEvent(&fxevent.Started{Err: errors.New("some error")})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "info", logs[0].Level())
+		assert.Equal(t, "warn", logs[1].Level())
+	})
+
+	t.Run("later options win", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithLogLevel(zerolog.InfoLevel),
+			WithLogLevel(zerolog.TraceLevel),
+		)
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, "trace", logs[0].Level())
+	})
+
+	t.Run("with event levels", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithLogLevel(zerolog.InfoLevel),
+			WithEventLevel((*fxevent.Provided)(nil), zerolog.TraceLevel),
+			WithEventErrorLevel((*fxevent.Invoked)(nil), zerolog.WarnLevel),
+		)
+		l.LogEvent(&fxevent.Provided{OutputTypeNames: []string{"*bytes.Buffer"}})
+		l.LogEvent(&fxevent.Started{})
+		l.LogEvent(&fxevent.Invoked{Err: errors.New("some error")})
+		l.LogEvent(&fxevent.Started{Err: errors.New("some error")})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 4)
+		assert.Equal(t, "trace", logs[0].Level())
+		assert.Equal(t, "info", logs[1].Level())
+		assert.Equal(t, "warn", logs[2].Level())
+		assert.Equal(t, "error", logs[3].Level())
+	})
+
+	t.Run("invalid levels", func(t *testing.T) {
+		core, _ := newZerologObservableLogger(zerolog.TraceLevel)
+
+		assert.PanicsWithError(t, "fxzerolog: invalid log level 42", func() {
+			New(core, WithLogLevel(zerolog.Level(42)))
+		})
+		assert.PanicsWithError(t, "fxzerolog: invalid error level -7", func() {
+			New(core, WithErrorLevel(zerolog.Level(-7)))
+		})
+		assert.PanicsWithError(t, "fxzerolog: invalid log level 8 for *fxevent.Run", func() {
+			New(core, WithEventLevel((*fxevent.Run)(nil), zerolog.Level(8)))
+		})
+		assert.PanicsWithError(t, "fxzerolog: nil event type", func() {
+			New(core, WithEventErrorLevel(nil, zerolog.WarnLevel))
+		})
+	})
+}
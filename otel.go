@@ -0,0 +1,74 @@
+package fxzerolog
+
+import (
+	"time"
+)
+
+// OTelFieldNames returns the field names used by WithOTel. Fields without an
+// OpenTelemetry semantic convention are namespaced under "fx.".
+func OTelFieldNames() FieldNames {
+	return otelFieldNames
+}
+
+var otelFieldNames = FieldNames{
+	Callee:              "code.function",
+	Caller:              "fx.caller",
+	Runtime:             "fx.duration",
+	RuntimeHuman:        "fx.runtime_human",
+	Slow:                "fx.slow",
+	Threshold:           "fx.threshold",
+	HookID:              "fx.hook_id",
+	RunID:               "fx.run_id",
+	Seq:                 "fx.seq",
+	Elapsed:             "fx.elapsed",
+	Phase:               "fx.phase",
+	StackTrace:          "fx.stacktrace",
+	ModuleTrace:         "fx.moduletrace",
+	Module:              "fx.module",
+	Type:                "fx.type",
+	Types:               "fx.types",
+	Constructor:         "code.function",
+	Decorator:           "code.function",
+	Function:            "code.function",
+	Stack:               "exception.stacktrace",
+	Name:                "code.function",
+	Kind:                "fx.kind",
+	Private:             "fx.private",
+	Signal:              "fx.signal",
+	Startup:             "fx.startup",
+	Provided:            "fx.provided",
+	Decorated:           "fx.decorated",
+	Replaced:            "fx.replaced",
+	Supplied:            "fx.supplied",
+	Constructors:        "fx.constructors",
+	ConstructorRuntime:  "fx.constructor_runtime",
+	SlowestConstructors: "fx.slowest_constructors",
+	SlowestHooks:        "fx.slowest_hooks",
+	Modules:             "fx.modules",
+	Shutdown:            "fx.shutdown",
+	Hooks:               "fx.hooks",
+	FailedHooks:         "fx.failed_hooks",
+	Budget:              "fx.budget",
+	OverBudget:          "fx.over_budget",
+	Status:              "fx.status",
+	Replayed:            "fx.replayed",
+	Discarded:           "fx.discarded",
+	Dropped:             "fx.dropped",
+	Namespace:           "code.namespace",
+	Error:               "exception.message",
+	ErrorType:           "exception.type",
+	Service:             "service.name",
+	Action:              "event.name",
+}
+
+// WithOTel makes the logger emit attributes following the OpenTelemetry
+// semantic conventions: keys are set to OTelFieldNames(), function names
+// are split into code.namespace and code.function, and durations are
+// encoded as float seconds, as OpenTelemetry recommends.
+func WithOTel() Option {
+	return func(l *ZerologLogger) {
+		names := otelFieldNames.withDefaults(&defaultFieldNames)
+		l.fields = &names
+		l.durations = durationFormat{unit: time.Second, float: true}
+	}
+}
@@ -0,0 +1,116 @@
+package fxzerolog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestSplitFunctionName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give          string
+		wantNamespace string
+		wantFunction  string
+	}{
+		{"go.uber.org/fx.New.func1()", "go.uber.org/fx", "New.func1"},
+		{"go.uber.org/fx.(*App).shutdowner-fm()", "go.uber.org/fx.(*App)", "shutdowner-fm"},
+		{"github.com/kestn/fxzerolog.Module.func1()", "github.com/kestn/fxzerolog", "Module.func1"},
+		{"main.main.func1()", "main", "main.func1"},
+		{"bytes.NewBuffer()", "bytes", "NewBuffer"},
+		{"main.(server).start", "main.(server)", "start"},
+		{"unqualified", "", "unqualified"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			namespace, function := splitFunctionName(tt.give)
+			assert.Equal(t, tt.wantNamespace, namespace)
+			assert.Equal(t, tt.wantFunction, function)
+		})
+	}
+}
+
+func TestWithOTel(t *testing.T) {
+	t.Run("attribute names", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithService("fx"), WithOTel())
+		for _, event := range allEvents() {
+			l.LogEvent(event)
+		}
+
+		allowed := map[string]struct{}{
+			"service.name":         {},
+			"event.name":           {},
+			"code.function":        {},
+			"code.namespace":       {},
+			"exception.type":       {},
+			"exception.message":    {},
+			"exception.stacktrace": {},
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, len(allEvents()))
+		for _, entry := range logs {
+			for key := range entry.Fields() {
+				if _, ok := allowed[key]; !ok {
+					assert.True(t, strings.HasPrefix(key, "fx."), "%q in %q", key, entry.Message())
+				}
+			}
+		}
+	})
+
+	t.Run("values", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithOTel())
+		l.LogEvent(&fxevent.Run{
+			Name:    "go.uber.org/fx.New.func1()",
+			Kind:    "provide",
+			Runtime: 1500 * time.Millisecond,
+		})
+		l.LogEvent(&fxevent.Invoked{
+			FunctionName: "main.main.func2()",
+			Err:          errors.New("some error"),
+			Trace:        "main.main()\n\t/app/main.go:12\n",
+		})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, map[string]any{
+			"event.name":     "run",
+			"code.function":  "New.func1",
+			"code.namespace": "go.uber.org/fx",
+			"fx.kind":        "provide",
+			"fx.duration":    1.5,
+		}, logs[0].Fields())
+		assert.Equal(t, map[string]any{
+			"event.name":           "invoked",
+			"code.function":        "main.func2",
+			"code.namespace":       "main",
+			"exception.message":    "some error",
+			"exception.type":       "*errors.errorString",
+			"exception.stacktrace": "main.main()\n\t/app/main.go:12\n",
+		}, logs[1].Fields())
+	})
+
+	t.Run("replaces previous preset", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithECS(), WithOTel())
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, map[string]any{"event.name": "started"}, logs[0].Fields())
+	})
+}
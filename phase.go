@@ -0,0 +1,74 @@
+package fxzerolog
+
+import (
+	"go.uber.org/fx/fxevent"
+)
+
+// Phase is a phase of the lifecycle of an Fx application.
+type Phase string
+
+// Phases of the lifecycle of an Fx application.
+const (
+	// PhaseProvide is when constructors, decorators and values are
+	// registered.
+	PhaseProvide Phase = "provide"
+	// PhaseInvoke is when invoked functions, and the constructors they
+	// depend on, run.
+	PhaseInvoke Phase = "invoke"
+	// PhaseStart is when OnStart hooks run.
+	PhaseStart Phase = "start"
+	// PhaseRunning is once the application has started.
+	PhaseRunning Phase = "running"
+	// PhaseStop is when OnStop hooks run.
+	PhaseStop Phase = "stop"
+	// PhaseRollback is when a failed start is being rolled back.
+	PhaseRollback Phase = "rollback"
+)
+
+// WithPhase adds a phase field with the lifecycle phase of the application,
+// as returned by Phase, to the lines of every Fx event.
+func WithPhase() Option {
+	return func(l *ZerologLogger) {
+		l.logPhase = true
+	}
+}
+
+// Phase returns the lifecycle phase of the application, as told by the
+// events logged so far, or an empty phase before the first event.
+func (l *ZerologLogger) Phase() Phase {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.phase
+}
+
+// nextPhase returns the phase entered with event, from phase.
+func nextPhase(phase Phase, event fxevent.Event) Phase {
+	switch e := event.(type) {
+	case *fxevent.LoggerInitialized, *fxevent.Provided, *fxevent.Supplied,
+		*fxevent.Replaced, *fxevent.Decorated:
+		if len(phase) == 0 {
+			return PhaseProvide
+		}
+	case *fxevent.Invoking:
+		return PhaseInvoke
+	case *fxevent.OnStartExecuting:
+		return PhaseStart
+	case *fxevent.Started:
+		// A failed start follows the rollback.
+		if e.Err == nil {
+			return PhaseRunning
+		}
+	case *fxevent.Stopping:
+		return PhaseStop
+	case *fxevent.OnStopExecuting:
+		// Hooks stopped without a signal, e.g. with App.Stop.
+		if phase == PhaseRunning {
+			return PhaseStop
+		}
+	case *fxevent.RollingBack:
+		return PhaseRollback
+	}
+
+	return phase
+}
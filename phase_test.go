@@ -0,0 +1,107 @@
+package fxzerolog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestNextPhase(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give  Phase
+		event fxevent.Event
+		want  Phase
+	}{
+		{"", &fxevent.LoggerInitialized{}, PhaseProvide},
+		{"", &fxevent.Provided{}, PhaseProvide},
+		{"", &fxevent.Supplied{}, PhaseProvide},
+		{PhaseInvoke, &fxevent.Decorated{}, PhaseInvoke},
+		{PhaseProvide, &fxevent.Invoking{}, PhaseInvoke},
+		{PhaseInvoke, &fxevent.Run{}, PhaseInvoke},
+		{PhaseInvoke, &fxevent.OnStartExecuting{}, PhaseStart},
+		{PhaseStart, &fxevent.OnStartExecuted{}, PhaseStart},
+		{PhaseStart, &fxevent.Started{}, PhaseRunning},
+		{PhaseRunning, &fxevent.Stopping{Signal: os.Interrupt}, PhaseStop},
+		{PhaseRunning, &fxevent.OnStopExecuting{}, PhaseStop},
+		{PhaseStop, &fxevent.Stopped{}, PhaseStop},
+		{PhaseStart, &fxevent.RollingBack{}, PhaseRollback},
+		{PhaseRollback, &fxevent.OnStopExecuting{}, PhaseRollback},
+		{PhaseRollback, &fxevent.RolledBack{}, PhaseRollback},
+		{PhaseRollback, &fxevent.Started{Err: errors.New("some error")}, PhaseRollback},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, nextPhase(tt.give, tt.event), "%v after %T", tt.give, tt.event)
+	}
+}
+
+func TestWithPhase(t *testing.T) {
+	t.Run("field", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithPhase())
+		assert.Empty(t, l.Phase())
+
+		l.LogEvent(&fxevent.Provided{ConstructorName: "main.new", OutputTypeNames: []string{"A"}})
+		l.LogEvent(&fxevent.Invoking{FunctionName: "main.run"})
+		l.LogEvent(&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "main.new"})
+		l.LogEvent(&fxevent.RollingBack{StartErr: errors.New("some error")})
+
+		var phases []any
+		for _, log := range observedLogs.TakeAll() {
+			phases = append(phases, log.Fields()["phase"])
+		}
+		assert.Equal(t, []any{"provide", "invoke", "start", "rollback"}, phases)
+		assert.Equal(t, PhaseRollback, l.Phase())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core)
+		l.LogEvent(&fxevent.Started{})
+
+		assert.Equal(t, PhaseRunning, l.Phase())
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.NotContains(t, logs[0].Fields(), "phase")
+	})
+
+	t.Run("app", func(t *testing.T) {
+		var eventLogger *ZerologLogger
+		var phases []Phase
+		app := fxtest.New(t,
+			fx.Supply(Config{Output: io.Discard}),
+			Module(),
+			fx.Populate(&eventLogger),
+			fx.Invoke(func(lc fx.Lifecycle) {
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error {
+						phases = append(phases, eventLogger.Phase())
+						return nil
+					},
+					OnStop: func(context.Context) error {
+						phases = append(phases, eventLogger.Phase())
+						return nil
+					},
+				})
+			}),
+		)
+		assert.Equal(t, PhaseInvoke, eventLogger.Phase())
+
+		app.RequireStart()
+		assert.Equal(t, PhaseRunning, eventLogger.Phase())
+
+		app.RequireStop()
+		assert.Equal(t, []Phase{PhaseStart, PhaseStop}, phases)
+	})
+}
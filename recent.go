@@ -0,0 +1,127 @@
+package fxzerolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// RecordedEvent is an Fx event recorded by WithRecentEvents.
+type RecordedEvent struct {
+	// Time is when the event was received.
+	Time time.Time
+	// Event is the event as emitted by Fx.
+	Event fxevent.Event
+}
+
+// WithRecentEvents records the last n events received, whatever their level
+// and even when dropped by WithFilter, to be returned by Recent and Dump.
+func WithRecentEvents(n int) Option {
+	return func(l *ZerologLogger) {
+		l.recent = &eventRing{size: n}
+	}
+}
+
+// Recent returns the events recorded by WithRecentEvents, oldest first.
+func (l *ZerologLogger) Recent() []RecordedEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.recent == nil {
+		return nil
+	}
+
+	return l.recent.all()
+}
+
+// Dump writes the events returned by Recent to w, as JSON lines such as
+// {"time":"2025-01-01T00:00:00Z","type":"OnStartExecuted","event":{...}}
+// where event holds the fields of the event. Errors, durations and signals
+// are written as strings.
+func (l *ZerologLogger) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range l.Recent() {
+		err := enc.Encode(recordedEventJSON{
+			Time:  e.Time,
+			Type:  reflect.TypeOf(e.Event).Elem().Name(),
+			Event: eventFields(e.Event),
+		})
+		if err != nil {
+			return fmt.Errorf("fxzerolog: dump events: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recordedEventJSON is a line written by Dump.
+type recordedEventJSON struct {
+	Time  time.Time      `json:"time"`
+	Type  string         `json:"type"`
+	Event map[string]any `json:"event"`
+}
+
+// eventFields returns the exported fields of event by name.
+func eventFields(event fxevent.Event) map[string]any {
+	v := reflect.ValueOf(event).Elem()
+	fields := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		switch value := v.Field(i).Interface().(type) {
+		case error:
+			fields[field.Name] = value.Error()
+		case time.Duration:
+			fields[field.Name] = value.String()
+		case os.Signal:
+			fields[field.Name] = value.String()
+		default:
+			fields[field.Name] = value
+		}
+	}
+
+	return fields
+}
+
+// eventRing holds the last size events received.
+type eventRing struct {
+	size   int
+	events []RecordedEvent
+	next   int
+}
+
+func (r *eventRing) validate() error {
+	if r.size < 0 {
+		return fmt.Errorf("fxzerolog: invalid number of recent events %d", r.size)
+	}
+
+	return nil
+}
+
+func (r *eventRing) record(e RecordedEvent) {
+	if r.size == 0 {
+		return
+	}
+
+	if len(r.events) < r.size {
+		r.events = append(r.events, e)
+		return
+	}
+
+	r.events[r.next] = e
+	r.next = (r.next + 1) % len(r.events)
+}
+
+func (r *eventRing) all() []RecordedEvent {
+	events := make([]RecordedEvent, 0, len(r.events))
+	events = append(events, r.events[r.next:]...)
+	return append(events, r.events[:r.next]...)
+}
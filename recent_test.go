@@ -0,0 +1,83 @@
+package fxzerolog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestWithRecentEvents(t *testing.T) {
+	events := []fxevent.Event{
+		&fxevent.Provided{ConstructorName: "main.new", OutputTypeNames: []string{"*main.A"}},
+		&fxevent.Invoking{FunctionName: "main.run"},
+		&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "main.new", Runtime: time.Millisecond},
+		&fxevent.Started{Err: errors.New("some error")},
+		&fxevent.Stopping{Signal: os.Interrupt},
+	}
+
+	t.Run("recent", func(t *testing.T) {
+		l := New(zerolog.Nop(),
+			WithRecentEvents(3),
+			WithFilter(MatchEventType((*fxevent.Invoking)(nil))),
+		)
+		l.now = fakeClock(time.Second)
+		assert.Empty(t, l.Recent())
+
+		l.LogEvent(events[0])
+		l.LogEvent(events[1])
+		recent := l.Recent()
+		require.Len(t, recent, 2)
+		assert.Same(t, events[0], recent[0].Event)
+		assert.Same(t, events[1], recent[1].Event, "filtered events should be recorded")
+
+		for _, event := range events[2:] {
+			l.LogEvent(event)
+		}
+		recent = l.Recent()
+		require.Len(t, recent, 3)
+		for i, e := range recent {
+			assert.Same(t, events[i+2], e.Event)
+			assert.Equal(t, time.Date(2025, 1, 1, 0, 0, i+3, 0, time.UTC), e.Time)
+		}
+	})
+
+	t.Run("dump", func(t *testing.T) {
+		l := New(zerolog.Nop(), WithRecentEvents(10))
+		l.now = fakeClock(time.Second)
+		for _, event := range events[2:] {
+			l.LogEvent(event)
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, l.Dump(&buf))
+		assert.Equal(t, strings.Join([]string{
+			`{"time":"2025-01-01T00:00:01Z","type":"OnStartExecuted","event":{"CallerName":"main.new","Err":null,"FunctionName":"hook.onStart","Method":"","Runtime":"1ms"}}`,
+			`{"time":"2025-01-01T00:00:02Z","type":"Started","event":{"Err":"some error"}}`,
+			`{"time":"2025-01-01T00:00:03Z","type":"Stopping","event":{"Signal":"interrupt"}}`,
+		}, "\n")+"\n", buf.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		l := New(zerolog.Nop())
+		l.LogEvent(events[0])
+
+		assert.Nil(t, l.Recent())
+		var buf bytes.Buffer
+		require.NoError(t, l.Dump(&buf))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.PanicsWithError(t, "fxzerolog: invalid number of recent events -1", func() {
+			New(zerolog.Nop(), WithRecentEvents(-1))
+		})
+	})
+}
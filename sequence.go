@@ -0,0 +1,16 @@
+package fxzerolog
+
+// WithSequence adds two fields to the lines of every Fx event, so that the
+// order of the events can be reconstructed regardless of the order lines are
+// ingested in:
+//
+//   - seq: the number of the event, counting from 1 in the order events are
+//     received. Lines logged for the same event share the same number.
+//   - elapsed: the time elapsed since the first event.
+//
+// Events are counted even when dropped by WithFilter.
+func WithSequence() Option {
+	return func(l *ZerologLogger) {
+		l.sequence = true
+	}
+}
@@ -0,0 +1,48 @@
+package fxzerolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestWithSequence(t *testing.T) {
+	t.Run("sequence", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithSequence(),
+			WithDurationUnit(time.Millisecond),
+			WithFilter(MatchEventType((*fxevent.Invoking)(nil))),
+		)
+		l.now = fakeClock(time.Millisecond)
+
+		l.LogEvent(&fxevent.Provided{ConstructorName: "main.new", OutputTypeNames: []string{"A", "B"}})
+		l.LogEvent(&fxevent.Invoking{FunctionName: "main.run"})
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 3)
+		for i, want := range []struct {
+			seq     float64
+			elapsed float64
+		}{{1, 0}, {1, 0}, {3, 2}} {
+			assert.Equal(t, want.seq, logs[i].Fields()["seq"])
+			assert.Equal(t, want.elapsed, logs[i].Fields()["elapsed"])
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core)
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.NotContains(t, logs[0].Fields(), "seq")
+		assert.NotContains(t, logs[0].Fields(), "elapsed")
+	})
+}
@@ -0,0 +1,123 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx/fxevent"
+)
+
+// SlowThresholds are the runtimes above which successful hooks and
+// constructors are considered slow. Zero thresholds are disabled.
+type SlowThresholds struct {
+	// OnStart applies to fxevent.OnStartExecuted.
+	OnStart time.Duration
+	// OnStop applies to fxevent.OnStopExecuted.
+	OnStop time.Duration
+	// Run applies to fxevent.Run, i.e. constructors, decorators and
+	// supplied values.
+	Run time.Duration
+}
+
+// slowFormat describes how slow events are logged.
+type slowFormat struct {
+	thresholds SlowThresholds
+	level      *zerolog.Level
+}
+
+// WithSlowThresholds escalates the lines of hooks and constructors running
+// longer than thresholds to the level set by WithSlowLevel, warn by default,
+// unless their level is already more severe. Lines disabled with
+// zerolog.Disabled stay disabled. Slow lines carry slow=true and the
+// threshold they breached.
+func WithSlowThresholds(thresholds SlowThresholds) Option {
+	return func(l *ZerologLogger) {
+		l.slow.thresholds = thresholds
+	}
+}
+
+// WithSlowLevel sets the level slow hooks and constructors are escalated to.
+// Defaults to zerolog.WarnLevel.
+func WithSlowLevel(level zerolog.Level) Option {
+	return func(l *ZerologLogger) {
+		l.slow.level = &level
+	}
+}
+
+func (f slowFormat) validate() error {
+	if f.thresholds.OnStart < 0 || f.thresholds.OnStop < 0 || f.thresholds.Run < 0 {
+		return fmt.Errorf("fxzerolog: invalid slow thresholds %+v", f.thresholds)
+	}
+
+	if f.level != nil && !validLevel(*f.level) {
+		return fmt.Errorf("fxzerolog: invalid slow level %d", *f.level)
+	}
+
+	return nil
+}
+
+func (f slowFormat) threshold(event fxevent.Event) time.Duration {
+	switch event.(type) {
+	case *fxevent.OnStartExecuted:
+		return f.thresholds.OnStart
+	case *fxevent.OnStopExecuted:
+		return f.thresholds.OnStop
+	case *fxevent.Run:
+		return f.thresholds.Run
+	}
+
+	return 0
+}
+
+// escalated reports whether the line of event is escalated for being slow.
+func (l *ZerologLogger) escalated(event fxevent.Event) bool {
+	if eventErr(event) != nil || l.levelFor(event) == zerolog.Disabled {
+		return false
+	}
+
+	threshold := l.slow.threshold(event)
+	if threshold <= 0 {
+		return false
+	}
+
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuted:
+		return e.Runtime > threshold
+	case *fxevent.OnStopExecuted:
+		return e.Runtime > threshold
+	case *fxevent.Run:
+		return e.Runtime > threshold
+	}
+
+	return false
+}
+
+// timedLogEvent starts the line of a successful event that took runtime,
+// escalated when slower than its threshold.
+func (l *ZerologLogger) timedLogEvent(event fxevent.Event, info eventInfo, runtime time.Duration) *zerolog.Event {
+	threshold := l.slow.threshold(event)
+	if threshold <= 0 || runtime <= threshold {
+		return l.logEvent(event, info)
+	}
+
+	slowLevel := l.slowLevel()
+	level := l.levelFor(event)
+	if level < slowLevel || level == zerolog.NoLevel {
+		level = slowLevel
+	}
+
+	f := l.fieldNames()
+	return l.newEvent(event, info, level, false).
+		Bool(f.Slow, true).
+		EmbedObject(l.durationField(f.Threshold, threshold))
+}
+
+// slowLevel returns the level slow events are escalated to.
+func (l *ZerologLogger) slowLevel() zerolog.Level {
+	if l.slow.level != nil {
+		return *l.slow.level
+	}
+
+	return zerolog.WarnLevel
+}
@@ -0,0 +1,109 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestWithSlowThresholds(t *testing.T) {
+	thresholds := SlowThresholds{
+		OnStart: 10 * time.Millisecond,
+		OnStop:  20 * time.Millisecond,
+		Run:     5 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name          string
+		give          fxevent.Event
+		wantLevel     string
+		wantThreshold any
+	}{
+		{
+			name:          "OnStartExecuted/Slow",
+			give:          &fxevent.OnStartExecuted{Runtime: 11 * time.Millisecond},
+			wantLevel:     "warn",
+			wantThreshold: "10ms",
+		},
+		{
+			name:      "OnStartExecuted/AtThreshold",
+			give:      &fxevent.OnStartExecuted{Runtime: 10 * time.Millisecond},
+			wantLevel: "debug",
+		},
+		{
+			name:          "OnStopExecuted/Slow",
+			give:          &fxevent.OnStopExecuted{Runtime: time.Second},
+			wantLevel:     "warn",
+			wantThreshold: "20ms",
+		},
+		{
+			name:      "OnStopExecuted/Fast",
+			give:      &fxevent.OnStopExecuted{Runtime: time.Millisecond},
+			wantLevel: "debug",
+		},
+		{
+			name:          "Run/Slow",
+			give:          &fxevent.Run{Kind: "provide", Runtime: 6 * time.Millisecond},
+			wantLevel:     "warn",
+			wantThreshold: "5ms",
+		},
+		{
+			name:      "Run/Error",
+			give:      &fxevent.Run{Kind: "provide", Runtime: time.Second, Err: errors.New("some error")},
+			wantLevel: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+			New(core, WithSlowThresholds(thresholds)).LogEvent(tt.give)
+
+			logs := observedLogs.TakeAll()
+			require.Len(t, logs, 1)
+			assert.Equal(t, tt.wantLevel, logs[0].Level())
+			if tt.wantThreshold != nil {
+				assert.Equal(t, true, logs[0].Fields()["slow"])
+				assert.Equal(t, tt.wantThreshold, logs[0].Fields()["threshold"])
+			} else {
+				assert.NotContains(t, logs[0].Fields(), "slow")
+				assert.NotContains(t, logs[0].Fields(), "threshold")
+			}
+		})
+	}
+
+	t.Run("slow level", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithSlowThresholds(thresholds),
+			WithSlowLevel(zerolog.InfoLevel),
+			WithDurationUnit(time.Millisecond),
+			WithEventLevel((*fxevent.OnStopExecuted)(nil), zerolog.ErrorLevel),
+			WithEventLevel((*fxevent.Run)(nil), zerolog.Disabled),
+		)
+		l.LogEvent(&fxevent.OnStartExecuted{Runtime: time.Second})
+		l.LogEvent(&fxevent.OnStopExecuted{Runtime: time.Second})
+		l.LogEvent(&fxevent.Run{Runtime: time.Second})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2, "disabled events should stay disabled")
+		assert.Equal(t, "info", logs[0].Level())
+		assert.Equal(t, float64(10), logs[0].Fields()["threshold"])
+		assert.Equal(t, "error", logs[1].Level(), "more severe levels are kept")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		core, _ := newZerologObservableLogger(zerolog.TraceLevel)
+		assert.Panics(t, func() {
+			New(core, WithSlowThresholds(SlowThresholds{Run: -time.Second}))
+		})
+		assert.PanicsWithError(t, "fxzerolog: invalid slow level 9", func() {
+			New(core, WithSlowLevel(zerolog.Level(9)))
+		})
+	})
+}
@@ -0,0 +1,221 @@
+package fxzerolog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// stackFormat describes how stack traces are encoded.
+type stackFormat struct {
+	// structured encodes frames as objects instead of strings.
+	structured bool
+	// trimRoots are the directories file paths are made relative to.
+	trimRoots []string
+	// trimModuleCache makes file paths in a module cache relative to it.
+	trimModuleCache bool
+	// dropPackages match the packages whose frames are dropped.
+	dropPackages []*regexp.Regexp
+	// maxFrames caps the number of frames, unless zero.
+	maxFrames int
+}
+
+// raw reports whether stack traces are encoded as reported by Fx.
+func (f stackFormat) raw() bool {
+	return !f.structured && !f.trimModuleCache && len(f.trimRoots) == 0 &&
+		len(f.dropPackages) == 0 && f.maxFrames == 0
+}
+
+// WithStructuredStacks encodes the stack traces and module traces of Fx
+// events, and the stack of failed invokes, as arrays of frame objects such
+// as {"function":"main.main","file":"/app/main.go","line":12} instead of
+// the strings formatted by Fx.
+func WithStructuredStacks() Option {
+	return func(l *ZerologLogger) {
+		l.stacks.structured = true
+	}
+}
+
+func (l *ZerologLogger) framesField(key string, frames []string) zerolog.LogObjectMarshaler {
+	return framesField{key: key, frames: frames, format: l.stacks}
+}
+
+func (l *ZerologLogger) traceField(key, trace string) zerolog.LogObjectMarshaler {
+	return traceField{key: key, trace: trace, format: l.stacks}
+}
+
+// framesField encodes the StackTrace or ModuleTrace of an Fx event.
+type framesField struct {
+	key    string
+	frames []string
+	format stackFormat
+}
+
+func (f framesField) MarshalZerologObject(e *zerolog.Event) {
+	if f.format.raw() {
+		e.Strs(f.key, f.frames)
+		return
+	}
+
+	frames := make([]frame, len(f.frames))
+	for i, s := range f.frames {
+		frames[i] = parseFrame(s)
+	}
+	frames = f.format.process(frames)
+
+	if f.format.structured {
+		e.Array(f.key, frameArray(frames))
+		return
+	}
+
+	strs := make([]string, len(frames))
+	for i, frame := range frames {
+		strs[i] = frame.String()
+	}
+	e.Strs(f.key, strs)
+}
+
+// traceField encodes the Trace of an fxevent.Invoked.
+type traceField struct {
+	key    string
+	trace  string
+	format stackFormat
+}
+
+func (f traceField) MarshalZerologObject(e *zerolog.Event) {
+	if f.format.raw() {
+		e.Str(f.key, f.trace)
+		return
+	}
+
+	frames := f.format.process(parseTrace(f.trace))
+
+	if f.format.structured {
+		e.Array(f.key, frameArray(frames))
+		return
+	}
+
+	var sb strings.Builder
+	for _, frame := range frames {
+		sb.WriteString(frame.function)
+		sb.WriteString("\n\t")
+		sb.WriteString(frame.file)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(frame.line))
+		sb.WriteByte('\n')
+	}
+	e.Str(f.key, sb.String())
+}
+
+// frameArray encodes frames as an array of objects.
+type frameArray []frame
+
+func (frames frameArray) MarshalZerologArray(a *zerolog.Array) {
+	for _, f := range frames {
+		a.Object(f)
+	}
+}
+
+// frame is a single frame of a stack trace reported by Fx.
+type frame struct {
+	function string
+	file     string
+	line     int
+}
+
+func (f frame) MarshalZerologObject(e *zerolog.Event) {
+	maybeStringField(e, "function", f.function)
+	maybeStringField(e, "file", f.file)
+	if f.line > 0 {
+		e.Int("line", f.line)
+	}
+}
+
+// String formats f as Fx does, e.g. "main.main (/app/main.go:12)".
+func (f frame) String() string {
+	var sb strings.Builder
+	sb.WriteString(f.function)
+	if len(f.file) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte('(')
+		sb.WriteString(f.file)
+		if f.line > 0 {
+			sb.WriteByte(':')
+			sb.WriteString(strconv.Itoa(f.line))
+		}
+		sb.WriteByte(')')
+	}
+
+	if sb.Len() == 0 {
+		return "unknown"
+	}
+
+	return sb.String()
+}
+
+// parseFrame parses a frame of the StackTrace or ModuleTrace of an Fx event,
+// formatted as "function (file:line)" where every part may be missing.
+func parseFrame(s string) frame {
+	var f frame
+	if !strings.HasSuffix(s, ")") {
+		f.function = s
+		return f
+	}
+
+	open := strings.LastIndex(s, " (")
+	switch {
+	case open >= 0:
+		f.function = s[:open]
+		s = s[open+2 : len(s)-1]
+	case strings.HasPrefix(s, "("):
+		s = s[1 : len(s)-1]
+	default:
+		f.function = s
+		return f
+	}
+
+	f.file, f.line = splitFileLine(s)
+	return f
+}
+
+// parseTrace parses the Trace of an fxevent.Invoked, formatted as one
+// function per line, each followed by a tab-indented "file:line" line.
+func parseTrace(trace string) []frame {
+	var frames []frame
+	for _, line := range strings.Split(trace, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			if len(frames) == 0 {
+				frames = append(frames, frame{})
+			}
+			f := &frames[len(frames)-1]
+			f.file, f.line = splitFileLine(line[1:])
+			continue
+		}
+
+		frames = append(frames, frame{function: line})
+	}
+
+	return frames
+}
+
+func splitFileLine(s string) (string, int) {
+	colon := strings.LastIndexByte(s, ':')
+	if colon < 0 {
+		return s, 0
+	}
+
+	line, err := strconv.Atoi(s[colon+1:])
+	if err != nil {
+		return s, 0
+	}
+
+	return s[:colon], line
+}
@@ -0,0 +1,129 @@
+package fxzerolog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestParseFrame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want frame
+	}{
+		{
+			give: "main.main (/app/main.go:12)",
+			want: frame{function: "main.main", file: "/app/main.go", line: 12},
+		},
+		{
+			give: "go.uber.org/fx.(*App).run (/go/pkg/mod/go.uber.org/fx@v1.23.0/app.go:486)",
+			want: frame{function: "go.uber.org/fx.(*App).run", file: "/go/pkg/mod/go.uber.org/fx@v1.23.0/app.go", line: 486},
+		},
+		{
+			give: "main.main (/app/main.go)",
+			want: frame{function: "main.main", file: "/app/main.go"},
+		},
+		{
+			give: "(/app/main.go:12)",
+			want: frame{file: "/app/main.go", line: 12},
+		},
+		{
+			give: "main.main",
+			want: frame{function: "main.main"},
+		},
+		{
+			give: "main.main.func1()",
+			want: frame{function: "main.main.func1()"},
+		},
+		{
+			give: "unknown",
+			want: frame{function: "unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, parseFrame(tt.give))
+		})
+	}
+}
+
+func TestParseTrace(t *testing.T) {
+	t.Parallel()
+
+	trace := "main.main.func2()\n\t/app/main.go:14\nmain.main()\n\t/app/main.go:12\nruntime.main\n\t/usr/lib/go/src/runtime/proc.go:272\n"
+	assert.Equal(t, []frame{
+		{function: "main.main.func2()", file: "/app/main.go", line: 14},
+		{function: "main.main()", file: "/app/main.go", line: 12},
+		{function: "runtime.main", file: "/usr/lib/go/src/runtime/proc.go", line: 272},
+	}, parseTrace(trace))
+
+	assert.Empty(t, parseTrace(""))
+}
+
+func TestWithStructuredStacks(t *testing.T) {
+	t.Run("all events", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithStructuredStacks())
+		for _, event := range allEvents() {
+			l.LogEvent(event)
+		}
+
+		var stacks int
+		for _, entry := range observedLogs.TakeAll() {
+			for _, key := range []string{"stacktrace", "moduletrace", "stack"} {
+				value, ok := entry.Fields()[key]
+				if !ok {
+					continue
+				}
+				stacks++
+
+				frames, ok := value.([]any)
+				require.True(t, ok, "%q in %q", key, entry.Message())
+				require.NotEmpty(t, frames)
+				for _, f := range frames {
+					assert.IsType(t, map[string]any{}, f)
+				}
+			}
+		}
+		assert.Equal(t, 17, stacks)
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithStructuredStacks())
+		l.LogEvent(&fxevent.Provided{
+			ConstructorName: "bytes.NewBuffer()",
+			StackTrace:      []string{"main.main (/app/main.go:12)", "runtime.main (/usr/lib/go/src/runtime/proc.go:272)"},
+			ModuleTrace:     []string{"main.main (/app/main.go:12)"},
+			OutputTypeNames: []string{"*bytes.Buffer"},
+		})
+		l.LogEvent(&fxevent.Invoked{
+			FunctionName: "main.run()",
+			Err:          errors.New("some error"),
+			Trace:        "main.main()\n\t/app/main.go:12\n",
+		})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 2)
+		assert.Equal(t, []any{
+			map[string]any{"function": "main.main", "file": "/app/main.go", "line": float64(12)},
+			map[string]any{"function": "runtime.main", "file": "/usr/lib/go/src/runtime/proc.go", "line": float64(272)},
+		}, logs[0].Fields()["stacktrace"])
+		assert.Equal(t, []any{
+			map[string]any{"function": "main.main", "file": "/app/main.go", "line": float64(12)},
+		}, logs[0].Fields()["moduletrace"])
+		assert.Equal(t, []any{
+			map[string]any{"function": "main.main()", "file": "/app/main.go", "line": float64(12)},
+		}, logs[1].Fields()["stack"])
+	})
+}
@@ -0,0 +1,110 @@
+package fxzerolog
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+)
+
+// Subscribe returns a channel receiving the events logged from now on, and a
+// function ending the subscription, which closes the channel.
+//
+// Logging never waits for subscribers: events are dropped when the channel
+// holds buffer events already, see DroppedEvents. A negative buffer is
+// treated as zero.
+func (l *ZerologLogger) Subscribe(buffer int) (<-chan fxevent.Event, func()) {
+	sub, cancel := l.subscribe(buffer)
+	return sub.events, cancel
+}
+
+func (l *ZerologLogger) subscribe(buffer int) (*subscription, func()) {
+	sub := &subscription{events: make(chan fxevent.Event, max(buffer, 0))}
+
+	l.mu.Lock()
+	if l.subscriptions == nil {
+		l.subscriptions = make(map[*subscription]struct{})
+	}
+	l.subscriptions[sub] = struct{}{}
+	l.mu.Unlock()
+
+	var once sync.Once
+	return sub, func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(l.subscriptions, sub)
+			close(sub.events)
+			l.mu.Unlock()
+		})
+	}
+}
+
+// DroppedEvents returns the number of events dropped because the channel of
+// a subscriber was full, over all subscriptions. See
+// EventSubscription.Dropped for the events dropped by a single subscription.
+func (l *ZerologLogger) DroppedEvents() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.dropped
+}
+
+// subscription is a subscriber to the events of a ZerologLogger.
+type subscription struct {
+	events chan fxevent.Event
+	// dropped is guarded by the mu of the ZerologLogger.
+	dropped uint64
+}
+
+// publish sends event to the subscribers that have room for it. It must be
+// called with l.mu held.
+func (l *ZerologLogger) publish(event fxevent.Event) {
+	for sub := range l.subscriptions {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped++
+			l.dropped++
+		}
+	}
+}
+
+// EventSubscription is a subscription to the events of the ZerologLogger
+// installed by Module, provided by ProvideSubscription.
+type EventSubscription struct {
+	// Events receives the events logged from the subscription on.
+	Events <-chan fxevent.Event
+	// Cancel ends the subscription, closing Events.
+	Cancel func()
+	// Dropped returns the number of events dropped because Events was full.
+	Dropped func() uint64
+}
+
+// ProvideSubscription returns an Fx option that provides an
+// EventSubscription to the ZerologLogger provided by Module, subscribed with
+// buffer when first requested. The subscription is shared by all the
+// functions it is injected into; call Subscribe on the ZerologLogger for
+// separate subscriptions. It is cancelled when the application stops, so
+// Events is closed before fxevent.Stopped.
+func ProvideSubscription(buffer int) fx.Option {
+	return fx.Provide(func(l *ZerologLogger, lc fx.Lifecycle) EventSubscription {
+		sub, cancel := l.subscribe(buffer)
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+		return EventSubscription{
+			Events: sub.events,
+			Cancel: cancel,
+			Dropped: func() uint64 {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+
+				return sub.dropped
+			},
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package fxzerolog
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run("subscribe", func(t *testing.T) {
+		l := New(zerolog.Nop(), WithFilter(MatchEventType((*fxevent.Started)(nil))))
+		l.LogEvent(&fxevent.Invoking{FunctionName: "main.run"})
+
+		events, cancel := l.Subscribe(2)
+		full, cancelFull := l.Subscribe(1)
+		defer cancelFull()
+
+		started := &fxevent.Started{}
+		stopping := &fxevent.Stopping{Signal: os.Interrupt}
+		stopped := &fxevent.Stopped{}
+		l.LogEvent(started)
+		l.LogEvent(stopping)
+
+		assert.Same(t, started, <-events, "filtered events should be published")
+		assert.Same(t, stopping, <-events)
+		assert.Same(t, started, <-full)
+		assert.Equal(t, uint64(1), l.DroppedEvents())
+
+		cancel()
+		cancel()
+		l.LogEvent(stopped)
+		_, ok := <-events
+		assert.False(t, ok, "channel should be closed")
+		assert.Same(t, stopped, <-full)
+		assert.Equal(t, uint64(1), l.DroppedEvents())
+	})
+
+	t.Run("provider", func(t *testing.T) {
+		var subscription EventSubscription
+		app := fxtest.New(t,
+			fx.Supply(Config{Output: io.Discard}),
+			Module(),
+			ProvideSubscription(100),
+			fx.Populate(&subscription),
+		)
+		app.RequireStart()
+
+		var started bool
+		for len(subscription.Events) > 0 {
+			if _, ok := (<-subscription.Events).(*fxevent.Started); ok {
+				started = true
+			}
+		}
+		assert.True(t, started)
+		assert.Zero(t, subscription.Dropped())
+
+		app.RequireStop()
+		for range subscription.Events {
+		}
+		subscription.Cancel()
+	})
+
+	t.Run("dropped per subscription", func(t *testing.T) {
+		var (
+			subscription EventSubscription
+			l            *ZerologLogger
+		)
+		app := fxtest.New(t,
+			fx.Supply(Config{Output: io.Discard}),
+			Module(),
+			ProvideSubscription(1),
+			fx.Populate(&subscription, &l),
+		)
+		events, cancel := l.Subscribe(2)
+		defer cancel()
+
+		l.LogEvent(&fxevent.Started{})
+		l.LogEvent(&fxevent.Stopping{Signal: os.Interrupt})
+		assert.Len(t, events, 2)
+		assert.NotZero(t, subscription.Dropped())
+		assert.Equal(t, l.DroppedEvents(), subscription.Dropped(),
+			"only the provided subscription should drop events")
+
+		app.RequireStart().RequireStop()
+	})
+
+	t.Run("negative buffer", func(t *testing.T) {
+		l := New(zerolog.Nop())
+		events, cancel := l.Subscribe(-1)
+		defer cancel()
+
+		l.LogEvent(&fxevent.Started{})
+		assert.Zero(t, cap(events))
+		assert.Equal(t, uint64(1), l.DroppedEvents())
+	})
+}
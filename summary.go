@@ -0,0 +1,318 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx/fxevent"
+)
+
+// WithStartupSummary logs a "startup summary" line at info level after a
+// successful fxevent.Started, with:
+//
+//   - startup: the time elapsed since the first event,
+//   - provided, decorated, replaced and supplied: the number of types,
+//   - constructors: the number of constructors actually run,
+//   - constructor_runtime: their cumulative runtime,
+//   - slowest_constructors and slowest_hooks: the n slowest constructors and
+//     OnStart hooks, with their runtime,
+//   - modules: the names of the modules seen.
+//
+// The keys are set by FieldNames. Events are accounted for even when dropped
+// by WithFilter.
+func WithStartupSummary(n int) Option {
+	return func(l *ZerologLogger) {
+		l.startup = &startupSummary{top: n}
+	}
+}
+
+// startupSummary accumulates the statistics of the startup of an app.
+type startupSummary struct {
+	top int
+
+	provided           int
+	decorated          int
+	replaced           int
+	supplied           int
+	constructors       int
+	constructorRuntime time.Duration
+	constructorTimings []timing
+	hookTimings        []timing
+	modules            map[string]struct{}
+}
+
+// timing is the runtime of a constructor or hook.
+type timing struct {
+	function string
+	caller   string
+	runtime  time.Duration
+}
+
+func (s *startupSummary) validate() error {
+	if s.top < 0 {
+		return fmt.Errorf("fxzerolog: invalid number of slowest entries %d", s.top)
+	}
+
+	return nil
+}
+
+func (s *startupSummary) observe(event fxevent.Event) {
+	if module, ok := eventModule(event); ok && len(module) > 0 {
+		if s.modules == nil {
+			s.modules = make(map[string]struct{})
+		}
+		s.modules[module] = struct{}{}
+	}
+
+	switch e := event.(type) {
+	case *fxevent.Provided:
+		if e.Err == nil {
+			s.provided += len(e.OutputTypeNames)
+		}
+	case *fxevent.Decorated:
+		if e.Err == nil {
+			s.decorated += len(e.OutputTypeNames)
+		}
+	case *fxevent.Replaced:
+		if e.Err == nil {
+			s.replaced += len(e.OutputTypeNames)
+		}
+	case *fxevent.Supplied:
+		if e.Err == nil {
+			s.supplied++
+		}
+	case *fxevent.Run:
+		if e.Err == nil && e.Kind == "provide" {
+			s.constructors++
+			s.constructorRuntime += e.Runtime
+			s.constructorTimings = insertSlowest(s.constructorTimings, s.top, timing{
+				function: e.Name,
+				runtime:  e.Runtime,
+			})
+		}
+	case *fxevent.OnStartExecuted:
+		if e.Err == nil {
+			s.hookTimings = insertSlowest(s.hookTimings, s.top, timing{
+				function: e.FunctionName,
+				caller:   e.CallerName,
+				runtime:  e.Runtime,
+			})
+		}
+	}
+}
+
+// insertSlowest inserts t into timings, sorted by decreasing runtime, and
+// keeps at most n of them.
+func insertSlowest(timings []timing, n int, t timing) []timing {
+	i := sort.Search(len(timings), func(i int) bool {
+		return timings[i].runtime < t.runtime
+	})
+	if i >= n {
+		return timings
+	}
+
+	timings = append(timings, timing{})
+	copy(timings[i+1:], timings[i:])
+	timings[i] = t
+	if len(timings) > n {
+		timings = timings[:n]
+	}
+
+	return timings
+}
+
+func (l *ZerologLogger) logSummaries(event fxevent.Event, info eventInfo) {
+	switch e := event.(type) {
+	case *fxevent.Started:
+		if e.Err == nil && l.startup != nil {
+			l.logStartupSummary(event, info)
+		}
+	case *fxevent.Stopped:
+		if l.shutdown != nil {
+			l.logShutdownSummary(event, info, e.Err)
+		}
+	}
+}
+
+func (l *ZerologLogger) logStartupSummary(event fxevent.Event, info eventInfo) {
+	l.mu.Lock()
+	startup := l.clock().Sub(l.first)
+	s := *l.startup
+	s.constructorTimings = append([]timing(nil), s.constructorTimings...)
+	s.hookTimings = append([]timing(nil), s.hookTimings...)
+	modules := make([]string, 0, len(s.modules))
+	for module := range s.modules {
+		modules = append(modules, module)
+	}
+	l.mu.Unlock()
+
+	sort.Strings(modules)
+	f := l.fieldNames()
+
+	l.newEvent(event, info, zerolog.InfoLevel, false).
+		EmbedObject(l.durationField(f.Startup, startup)).
+		Int(f.Provided, s.provided).
+		Int(f.Decorated, s.decorated).
+		Int(f.Replaced, s.replaced).
+		Int(f.Supplied, s.supplied).
+		Int(f.Constructors, s.constructors).
+		EmbedObject(l.durationField(f.ConstructorRuntime, s.constructorRuntime)).
+		Array(f.SlowestConstructors, timingArray{l: l, functionKey: f.Name, timings: s.constructorTimings}).
+		Array(f.SlowestHooks, timingArray{l: l, functionKey: f.Callee, callerKey: f.Caller, timings: s.hookTimings}).
+		Strs(f.Modules, modules).
+		Msg("startup summary")
+}
+
+// WithShutdownSummary logs a "shutdown summary" line on fxevent.Stopped,
+// whether the application stopped cleanly or not, with:
+//
+//   - signal: the signal received, if any,
+//   - shutdown: the time elapsed since the application started stopping,
+//   - hooks: every OnStop hook, in execution order, with its runtime and a
+//     status of "ok" or "failed",
+//   - failed_hooks: the names of the hooks that failed,
+//   - budget and over_budget: the budget, unless zero, and whether the
+//     shutdown took longer,
+//   - the error of the shutdown, if any.
+//
+// The line is logged at info level, or warn level when the shutdown failed,
+// a hook failed or the budget is exceeded. The keys are set by FieldNames.
+func WithShutdownSummary(budget time.Duration) Option {
+	return func(l *ZerologLogger) {
+		l.shutdown = &shutdownSummary{budget: budget}
+	}
+}
+
+// shutdownSummary accumulates the statistics of the shutdown of an app.
+type shutdownSummary struct {
+	budget time.Duration
+
+	signal   string
+	stopping time.Time
+	hooks    []hookResult
+}
+
+// hookResult is the result of an OnStop hook.
+type hookResult struct {
+	timing
+	err error
+}
+
+func (s *shutdownSummary) validate() error {
+	if s.budget < 0 {
+		return fmt.Errorf("fxzerolog: invalid shutdown budget %v", s.budget)
+	}
+
+	return nil
+}
+
+func (s *shutdownSummary) observe(event fxevent.Event, now time.Time) {
+	switch e := event.(type) {
+	case *fxevent.Stopping:
+		s.signal = strings.ToUpper(e.Signal.String())
+		s.stopping = now
+	case *fxevent.OnStopExecuting:
+		if s.stopping.IsZero() {
+			s.stopping = now
+		}
+	case *fxevent.OnStopExecuted:
+		s.hooks = append(s.hooks, hookResult{
+			timing: timing{
+				function: e.FunctionName,
+				caller:   e.CallerName,
+				runtime:  e.Runtime,
+			},
+			err: e.Err,
+		})
+	case *fxevent.RollingBack, *fxevent.RolledBack:
+		// OnStop hooks run while rolling back are not part of a shutdown.
+		*s = shutdownSummary{budget: s.budget}
+	}
+}
+
+func (l *ZerologLogger) logShutdownSummary(event fxevent.Event, info eventInfo, err error) {
+	l.mu.Lock()
+	s := *l.shutdown
+	*l.shutdown = shutdownSummary{budget: s.budget}
+	var shutdown time.Duration
+	if !s.stopping.IsZero() {
+		shutdown = l.clock().Sub(s.stopping)
+	}
+	l.mu.Unlock()
+
+	f := l.fieldNames()
+	overBudget := s.budget > 0 && shutdown > s.budget
+	failed := make([]string, 0)
+	for _, hook := range s.hooks {
+		if hook.err != nil {
+			failed = append(failed, hook.function)
+		}
+	}
+
+	level := zerolog.InfoLevel
+	if err != nil || len(failed) > 0 || overBudget {
+		level = zerolog.WarnLevel
+	}
+
+	zEvent := l.newEvent(event, info, level, err != nil)
+	maybeStringField(zEvent, f.Signal, s.signal).
+		EmbedObject(l.durationField(f.Shutdown, shutdown)).
+		Array(f.Hooks, hookResultArray{l: l, hooks: s.hooks}).
+		Strs(f.FailedHooks, failed)
+	if s.budget > 0 {
+		zEvent.
+			EmbedObject(l.durationField(f.Budget, s.budget)).
+			Bool(f.OverBudget, overBudget)
+	}
+	if err != nil {
+		zEvent.EmbedObject(l.errorField(err))
+	}
+	zEvent.Msg("shutdown summary")
+}
+
+// hookResultArray encodes the results of OnStop hooks as an array of objects.
+type hookResultArray struct {
+	l     *ZerologLogger
+	hooks []hookResult
+}
+
+func (a hookResultArray) MarshalZerologArray(arr *zerolog.Array) {
+	f := a.l.fieldNames()
+	for _, hook := range a.hooks {
+		e := zerolog.Dict().
+			Str(f.Callee, hook.function).
+			Str(f.Caller, hook.caller).
+			EmbedObject(a.l.durationField(f.Runtime, hook.runtime))
+		if hook.err != nil {
+			e.Str(f.Status, "failed").
+				EmbedObject(a.l.errorField(hook.err))
+		} else {
+			e.Str(f.Status, "ok")
+		}
+		arr.Dict(e)
+	}
+}
+
+// timingArray encodes timings as an array of objects.
+type timingArray struct {
+	l           *ZerologLogger
+	functionKey string
+	callerKey   string
+	timings     []timing
+}
+
+func (a timingArray) MarshalZerologArray(arr *zerolog.Array) {
+	f := a.l.fieldNames()
+	for _, t := range a.timings {
+		e := zerolog.Dict().
+			Str(a.functionKey, t.function).
+			EmbedObject(a.l.durationField(f.Runtime, t.runtime))
+		if len(a.callerKey) > 0 {
+			e.Str(a.callerKey, t.caller)
+		}
+		arr.Dict(e)
+	}
+}
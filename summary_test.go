@@ -0,0 +1,287 @@
+package fxzerolog
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+// fakeClock returns a clock advancing by step at each reading.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestWithStartupSummary(t *testing.T) {
+	t.Run("summary", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core,
+			WithLogLevel(zerolog.DebugLevel),
+			WithStartupSummary(2),
+			WithFilter(MatchEventType((*fxevent.Run)(nil))),
+		)
+		l.now = fakeClock(time.Millisecond)
+
+		for _, event := range []fxevent.Event{
+			&fxevent.Provided{OutputTypeNames: []string{"fx.Lifecycle"}},
+			&fxevent.Provided{ModuleName: "db", OutputTypeNames: []string{"*sql.DB", "db.Repository"}},
+			&fxevent.Provided{ModuleName: "http", Err: errors.New("some error")},
+			&fxevent.Decorated{ModuleName: "http", OutputTypeNames: []string{"*http.Server"}},
+			&fxevent.Replaced{OutputTypeNames: []string{"io.Writer"}},
+			&fxevent.Supplied{TypeName: "fxzerolog.Config"},
+			&fxevent.Run{Name: "db.New()", Kind: "provide", Runtime: 30 * time.Millisecond},
+			&fxevent.Run{Name: "http.New()", Kind: "provide", Runtime: 50 * time.Millisecond},
+			&fxevent.Run{Name: "cache.New()", Kind: "provide", Runtime: 10 * time.Millisecond},
+			&fxevent.Run{Name: "stub(fxzerolog.Config)", Kind: "supply", Runtime: time.Millisecond},
+			&fxevent.Run{Name: "broken.New()", Kind: "provide", Err: errors.New("some error")},
+			&fxevent.OnStartExecuted{FunctionName: "db.start", CallerName: "db.New", Runtime: 5 * time.Millisecond},
+			&fxevent.OnStartExecuted{FunctionName: "http.start", CallerName: "http.New", Runtime: 2 * time.Millisecond},
+			&fxevent.OnStartExecuted{FunctionName: "cache.start", CallerName: "cache.New", Runtime: 7 * time.Millisecond},
+			&fxevent.Started{},
+		} {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.NotEmpty(t, logs)
+		got := logs[len(logs)-1]
+		assert.Equal(t, "startup summary", got.Message())
+		assert.Equal(t, "info", got.Level())
+		assert.Equal(t, map[string]any{
+			"startup":             "15ms",
+			"provided":            float64(3),
+			"decorated":           float64(1),
+			"replaced":            float64(1),
+			"supplied":            float64(1),
+			"constructors":        float64(3),
+			"constructor_runtime": "90ms",
+			"slowest_constructors": []any{
+				map[string]any{"name": "http.New()", "runtime": "50ms"},
+				map[string]any{"name": "db.New()", "runtime": "30ms"},
+			},
+			"slowest_hooks": []any{
+				map[string]any{"callee": "cache.start", "caller": "cache.New", "runtime": "7ms"},
+				map[string]any{"callee": "db.start", "caller": "db.New", "runtime": "5ms"},
+			},
+			"modules": []any{"db", "http"},
+		}, got.Fields())
+	})
+
+	t.Run("field names", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithStartupSummary(1), WithECS(), WithSequence(), WithPhase())
+		l.now = fakeClock(time.Millisecond)
+		l.LogEvent(&fxevent.Provided{OutputTypeNames: []string{"fx.Lifecycle"}})
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 3)
+		assert.Equal(t, "startup summary", logs[2].Message())
+		assert.Equal(t, map[string]any{
+			"event.action":            "started",
+			"event.outcome":           "success",
+			"event.sequence":          float64(2),
+			"fx.elapsed":              float64(time.Millisecond),
+			"fx.phase":                "running",
+			"fx.startup":              float64(2 * time.Millisecond),
+			"fx.provided":             float64(1),
+			"fx.decorated":            float64(0),
+			"fx.replaced":             float64(0),
+			"fx.supplied":             float64(0),
+			"fx.constructors":         float64(0),
+			"fx.constructor_runtime":  float64(0),
+			"fx.slowest_constructors": []any{},
+			"fx.slowest_hooks":        []any{},
+			"fx.modules":              []any{},
+		}, logs[2].Fields())
+	})
+
+	t.Run("not on failure", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithStartupSummary(3))
+		l.LogEvent(&fxevent.Started{Err: errors.New("some error")})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, "start failed", logs[0].Message())
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core)
+		l.LogEvent(&fxevent.Started{})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		assert.Equal(t, "started", logs[0].Message())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		core, _ := newZerologObservableLogger(zerolog.TraceLevel)
+		assert.PanicsWithError(t, "fxzerolog: invalid number of slowest entries -1", func() {
+			New(core, WithStartupSummary(-1))
+		})
+	})
+}
+
+func TestInsertSlowest(t *testing.T) {
+	t.Parallel()
+
+	var timings []timing
+	for _, runtime := range []time.Duration{3, 1, 4, 1, 5, 9, 2, 6} {
+		timings = insertSlowest(timings, 3, timing{runtime: runtime})
+	}
+
+	assert.Equal(t, []timing{{runtime: 9}, {runtime: 6}, {runtime: 5}}, timings)
+	assert.Empty(t, insertSlowest(nil, 0, timing{runtime: 1}))
+}
+
+func TestWithShutdownSummary(t *testing.T) {
+	t.Run("clean", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithShutdownSummary(time.Second))
+		l.now = fakeClock(time.Millisecond)
+
+		for _, event := range []fxevent.Event{
+			&fxevent.Started{},
+			&fxevent.Stopping{Signal: os.Interrupt},
+			&fxevent.OnStopExecuting{FunctionName: "http.stop", CallerName: "http.New"},
+			&fxevent.OnStopExecuted{FunctionName: "http.stop", CallerName: "http.New", Runtime: 3 * time.Millisecond},
+			&fxevent.Stopped{},
+		} {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 5)
+		got := logs[4]
+		assert.Equal(t, "shutdown summary", got.Message())
+		assert.Equal(t, "info", got.Level())
+		assert.Equal(t, map[string]any{
+			"signal":   "INTERRUPT",
+			"shutdown": "4ms",
+			"hooks": []any{
+				map[string]any{"callee": "http.stop", "caller": "http.New", "runtime": "3ms", "status": "ok"},
+			},
+			"failed_hooks": []any{},
+			"budget":       "1s",
+			"over_budget":  false,
+		}, got.Fields())
+	})
+
+	t.Run("failed over budget", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithShutdownSummary(time.Millisecond))
+		l.now = fakeClock(time.Millisecond)
+
+		for _, event := range []fxevent.Event{
+			&fxevent.OnStopExecuting{FunctionName: "http.stop", CallerName: "http.New"},
+			&fxevent.OnStopExecuted{FunctionName: "http.stop", CallerName: "http.New", Err: errors.New("some error")},
+			&fxevent.OnStopExecuting{FunctionName: "db.stop", CallerName: "db.New"},
+			&fxevent.OnStopExecuted{FunctionName: "db.stop", CallerName: "db.New", Runtime: time.Millisecond},
+			&fxevent.Stopped{Err: errors.New("stop error")},
+		} {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 6)
+		assert.Equal(t, "stop failed", logs[4].Message())
+		got := logs[5]
+		assert.Equal(t, "shutdown summary", got.Message())
+		assert.Equal(t, "warn", got.Level())
+		assert.Equal(t, map[string]any{
+			"shutdown": "5ms",
+			"hooks": []any{
+				map[string]any{"callee": "http.stop", "caller": "http.New", "runtime": "0s", "status": "failed", "error": "some error"},
+				map[string]any{"callee": "db.stop", "caller": "db.New", "runtime": "1ms", "status": "ok"},
+			},
+			"failed_hooks": []any{"http.stop"},
+			"budget":       "1ms",
+			"over_budget":  true,
+			"error":        "stop error",
+		}, got.Fields())
+	})
+
+	t.Run("field names", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithShutdownSummary(time.Second), WithECS(), WithSequence(), WithPhase())
+		l.now = fakeClock(time.Millisecond)
+
+		for _, event := range []fxevent.Event{
+			&fxevent.Started{},
+			&fxevent.Stopping{Signal: os.Interrupt},
+			&fxevent.OnStopExecuting{FunctionName: "http.stop", CallerName: "http.New"},
+			&fxevent.OnStopExecuted{FunctionName: "http.stop", CallerName: "http.New", Runtime: time.Millisecond},
+			&fxevent.Stopped{},
+		} {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 5)
+		assert.Equal(t, "shutdown summary", logs[4].Message())
+		assert.Equal(t, map[string]any{
+			"event.action":   "stopped",
+			"event.outcome":  "success",
+			"event.sequence": float64(5),
+			"fx.elapsed":     float64(4 * time.Millisecond),
+			"fx.phase":       "stop",
+			"fx.signal":      "INTERRUPT",
+			"fx.shutdown":    float64(4 * time.Millisecond),
+			"fx.hooks": []any{
+				map[string]any{
+					"log.origin.function": "http.stop",
+					"fx.caller":           "http.New",
+					"event.duration":      float64(time.Millisecond),
+					"fx.status":           "ok",
+				},
+			},
+			"fx.failed_hooks": []any{},
+			"fx.budget":       float64(time.Second),
+			"fx.over_budget":  false,
+		}, logs[4].Fields())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithShutdownSummary(0))
+		l.now = fakeClock(time.Millisecond)
+
+		for _, event := range []fxevent.Event{
+			&fxevent.RollingBack{StartErr: errors.New("some error")},
+			&fxevent.OnStopExecuting{FunctionName: "http.stop", CallerName: "http.New"},
+			&fxevent.OnStopExecuted{FunctionName: "http.stop", CallerName: "http.New"},
+			&fxevent.RolledBack{},
+			&fxevent.Stopped{},
+		} {
+			l.LogEvent(event)
+		}
+
+		logs := observedLogs.TakeAll()
+		require.NotEmpty(t, logs)
+		got := logs[len(logs)-1]
+		assert.Equal(t, "shutdown summary", got.Message())
+		assert.Equal(t, map[string]any{
+			"shutdown":     "0s",
+			"hooks":        []any{},
+			"failed_hooks": []any{},
+		}, got.Fields())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		core, _ := newZerologObservableLogger(zerolog.TraceLevel)
+		assert.PanicsWithError(t, "fxzerolog: invalid shutdown budget -1s", func() {
+			New(core, WithShutdownSummary(-time.Second))
+		})
+	})
+}
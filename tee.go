@@ -0,0 +1,118 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// panicOutput is where the panics of the loggers passed to Tee and Async
+// are reported.
+var panicOutput io.Writer = os.Stderr
+
+// Tee returns an fxevent.Logger passing every event to each of loggers, in
+// order. A panic in a logger is recovered and reported to os.Stderr, so that
+// it neither prevents the other loggers from receiving the event nor breaks
+// the application. Wrap slow loggers with Async.
+func Tee(loggers ...fxevent.Logger) fxevent.Logger {
+	return teeLogger(append([]fxevent.Logger(nil), loggers...))
+}
+
+type teeLogger []fxevent.Logger
+
+func (t teeLogger) LogEvent(event fxevent.Event) {
+	for _, logger := range t {
+		safeLogEvent(logger, event)
+	}
+}
+
+// safeLogEvent passes event to logger, recovering from its panics.
+func safeLogEvent(logger fxevent.Logger, event fxevent.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(panicOutput, "fxzerolog: %T panicked logging %T: %v\n", logger, event, r)
+		}
+	}()
+
+	logger.LogEvent(event)
+}
+
+var _ fxevent.Logger = (*AsyncLogger)(nil)
+
+// AsyncLogger is an fxevent.Logger passing events to another logger from a
+// separate goroutine, see Async.
+type AsyncLogger struct {
+	logger  fxevent.Logger
+	events  chan fxevent.Event
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Async returns an AsyncLogger passing events to logger from a separate
+// goroutine, so that a slow logger does not delay the application. Up to
+// buffer events are queued; events logged while the queue is full are
+// dropped, see Dropped. A negative buffer is treated as zero. Panics in
+// logger are recovered and reported as Tee does.
+//
+// Close the AsyncLogger once the application has stopped to flush the queue.
+func Async(logger fxevent.Logger, buffer int) *AsyncLogger {
+	a := &AsyncLogger{
+		logger: logger,
+		events: make(chan fxevent.Event, max(buffer, 0)),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+
+	return a
+}
+
+// LogEvent queues event for the wrapped logger, or drops it if the queue is
+// full or a is closed.
+func (a *AsyncLogger) LogEvent(event fxevent.Event) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		a.dropped.Add(1)
+		return
+	}
+
+	select {
+	case a.events <- event:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events dropped.
+func (a *AsyncLogger) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Close waits for the events queued to be logged, and stops the goroutine
+// of a. Events logged afterwards are dropped.
+func (a *AsyncLogger) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.events)
+	}
+	a.mu.Unlock()
+
+	<-a.done
+}
+
+func (a *AsyncLogger) run() {
+	defer close(a.done)
+
+	for event := range a.events {
+		safeLogEvent(a.logger, event)
+	}
+}
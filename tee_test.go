@@ -0,0 +1,126 @@
+package fxzerolog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+// recordingLogger records the events it receives.
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []fxevent.Event
+}
+
+func (r *recordingLogger) LogEvent(event fxevent.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recordingLogger) recorded() []fxevent.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]fxevent.Event(nil), r.events...)
+}
+
+type panickingLogger struct{}
+
+func (panickingLogger) LogEvent(fxevent.Event) {
+	panic("broken sink")
+}
+
+func capturePanics(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	output := panicOutput
+	panicOutput = &buf
+	t.Cleanup(func() {
+		panicOutput = output
+	})
+
+	return &buf
+}
+
+func TestTee(t *testing.T) {
+	panics := capturePanics(t)
+
+	core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+	first, last := &recordingLogger{}, &recordingLogger{}
+	tee := Tee(first, panickingLogger{}, New(core), last)
+
+	started := &fxevent.Started{}
+	stopped := &fxevent.Stopped{}
+	tee.LogEvent(started)
+	tee.LogEvent(stopped)
+
+	assert.Equal(t, []fxevent.Event{started, stopped}, first.recorded())
+	assert.Equal(t, []fxevent.Event{started, stopped}, last.recorded())
+	assert.Len(t, observedLogs.TakeAll(), 1)
+	assert.Equal(t,
+		"fxzerolog: fxzerolog.panickingLogger panicked logging *fxevent.Started: broken sink\n"+
+			"fxzerolog: fxzerolog.panickingLogger panicked logging *fxevent.Stopped: broken sink\n",
+		panics.String())
+}
+
+// blockingLogger blocks until released.
+type blockingLogger struct {
+	recordingLogger
+	release chan struct{}
+}
+
+func (b *blockingLogger) LogEvent(event fxevent.Event) {
+	<-b.release
+	b.recordingLogger.LogEvent(event)
+}
+
+func TestAsync(t *testing.T) {
+	t.Run("queue", func(t *testing.T) {
+		sink := &blockingLogger{release: make(chan struct{})}
+		async := Async(sink, 1)
+
+		// The first event is being logged, the second is queued.
+		events := []fxevent.Event{&fxevent.Started{}, &fxevent.Stopping{}, &fxevent.Stopped{}}
+		async.LogEvent(events[0])
+		require.Eventually(t, func() bool {
+			return len(async.events) == 0
+		}, time.Second, time.Millisecond)
+		async.LogEvent(events[1])
+		async.LogEvent(events[2])
+		assert.Equal(t, uint64(1), async.Dropped())
+
+		close(sink.release)
+		async.Close()
+		async.Close()
+		assert.Equal(t, events[:2], sink.recorded())
+
+		async.LogEvent(events[2])
+		assert.Equal(t, uint64(2), async.Dropped())
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		panics := capturePanics(t)
+
+		async := Async(panickingLogger{}, 10)
+		async.LogEvent(&fxevent.Started{})
+		async.Close()
+
+		assert.Equal(t, "fxzerolog: fxzerolog.panickingLogger panicked logging *fxevent.Started: broken sink\n", panics.String())
+	})
+	t.Run("negative buffer", func(t *testing.T) {
+		sink := &recordingLogger{}
+		async := Async(sink, -1)
+		async.LogEvent(&fxevent.Started{})
+		async.Close()
+
+		assert.Zero(t, cap(async.events))
+		assert.Len(t, sink.recorded(), 1-int(async.Dropped()))
+	})
+}
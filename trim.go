@@ -0,0 +1,179 @@
+package fxzerolog
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// moduleCacheDir is the directory of the module cache within GOPATH.
+const moduleCacheDir = "/pkg/mod/"
+
+// defaultDropPackages are the packages whose frames are dropped by
+// WithDropFrames when no pattern is given.
+var defaultDropPackages = []string{"runtime", "go.uber.org/fx", "go.uber.org/dig"}
+
+// WithTrimPaths shortens the file paths of stack frames:
+//
+//   - paths within a module cache are made relative to it, e.g.
+//     "go.uber.org/fx@v1.23.0/app.go",
+//   - paths within GOROOT are made relative to its source directory, e.g.
+//     "runtime/proc.go",
+//   - paths within the main module, or within any of roots, are made relative
+//     to it, e.g. "cmd/server/main.go".
+//
+// The main module is located from the main function on the stack of the
+// goroutine calling New, and is not trimmed if it cannot be found.
+func WithTrimPaths(roots ...string) Option {
+	return func(l *ZerologLogger) {
+		l.stacks.trimModuleCache = true
+		for _, root := range append(roots, mainModuleDir(), gorootSourceDir()) {
+			if len(root) > 0 {
+				l.stacks.trimRoots = append(l.stacks.trimRoots, strings.TrimSuffix(root, "/")+"/")
+			}
+		}
+	}
+}
+
+// WithDropFrames drops the stack frames of functions in the packages matched
+// by patterns, or in runtime, go.uber.org/fx and go.uber.org/dig when none
+// is given. A pattern matches a package and its sub-packages, e.g.
+// "go.uber.org/fx" matches "go.uber.org/fx/internal/lifecycle", and may use
+// the '*' and '?' wildcards, e.g. "github.com/acme/*/internal".
+func WithDropFrames(patterns ...string) Option {
+	if len(patterns) == 0 {
+		patterns = defaultDropPackages
+	}
+
+	globs := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		globs[i] = compileGlob(pattern)
+	}
+
+	return func(l *ZerologLogger) {
+		l.stacks.dropPackages = append(l.stacks.dropPackages, globs...)
+	}
+}
+
+// WithMaxFrames keeps at most n frames of each stack trace, after the frames
+// dropped by WithDropFrames.
+func WithMaxFrames(n int) Option {
+	return func(l *ZerologLogger) {
+		l.stacks.maxFrames = n
+	}
+}
+
+// process drops, trims and caps frames according to f.
+func (f stackFormat) process(frames []frame) []frame {
+	kept := frames[:0]
+	for _, frame := range frames {
+		if f.dropped(frame) {
+			continue
+		}
+		if f.maxFrames > 0 && len(kept) == f.maxFrames {
+			break
+		}
+
+		frame.file = f.trimPath(frame.file)
+		kept = append(kept, frame)
+	}
+
+	return kept
+}
+
+func (f stackFormat) dropped(frame frame) bool {
+	if len(f.dropPackages) == 0 || len(frame.function) == 0 {
+		return false
+	}
+
+	pkg := functionPackage(frame.function)
+	for _, glob := range f.dropPackages {
+		for sub := pkg; ; {
+			if glob.MatchString(sub) {
+				return true
+			}
+			slash := strings.LastIndexByte(sub, '/')
+			if slash < 0 {
+				break
+			}
+			sub = sub[:slash]
+		}
+	}
+
+	return false
+}
+
+func (f stackFormat) trimPath(file string) string {
+	for _, root := range f.trimRoots {
+		if strings.HasPrefix(file, root) {
+			return file[len(root):]
+		}
+	}
+
+	if f.trimModuleCache {
+		if i := strings.Index(file, moduleCacheDir); i >= 0 {
+			return file[i+len(moduleCacheDir):]
+		}
+	}
+
+	return file
+}
+
+// functionPackage returns the package path of a Go function name.
+func functionPackage(name string) string {
+	namespace, _ := splitFunctionName(name)
+	if i := strings.Index(namespace, ".("); i >= 0 {
+		return namespace[:i]
+	}
+
+	return namespace
+}
+
+// gorootSourceDir returns the directory of the standard library sources, as
+// recorded in the stack frames of the running binary.
+func gorootSourceDir() string {
+	fn := runtime.FuncForPC(reflect.ValueOf(strings.Cut).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	file, _ := fn.FileLine(fn.Entry())
+	dir, ok := strings.CutSuffix(file, "strings/strings.go")
+	if !ok || !path.IsAbs(dir) {
+		return ""
+	}
+
+	return dir
+}
+
+// mainModuleDir returns the root directory of the main module, as recorded
+// in the stack frames of the running binary, by locating the main function
+// on the stack of the calling goroutine.
+func mainModuleDir() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || len(info.Main.Path) == 0 {
+		return ""
+	}
+
+	pcs := make([]uintptr, 128)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "main.main" {
+			// The main package may live in a sub-directory of the module,
+			// e.g. cmd/server.
+			dir := path.Dir(frame.File)
+			sub := strings.TrimPrefix(info.Path, info.Main.Path)
+			if root, ok := strings.CutSuffix(dir, sub); ok && path.IsAbs(root) {
+				return root
+			}
+			return ""
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package fxzerolog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+func TestFunctionPackage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "go.uber.org/fx", functionPackage("go.uber.org/fx.New"))
+	assert.Equal(t, "go.uber.org/fx", functionPackage("go.uber.org/fx.(*App).run-fm()"))
+	assert.Equal(t, "runtime", functionPackage("runtime.main"))
+	assert.Equal(t, "main", functionPackage("main.main.func1()"))
+}
+
+func TestGorootSourceDir(t *testing.T) {
+	t.Parallel()
+
+	dir := gorootSourceDir()
+	assert.True(t, strings.HasSuffix(dir, "/src/"), dir)
+}
+
+func TestStackTrimming(t *testing.T) {
+	stackTrace := []string{
+		"go.uber.org/fx.New (/home/kestn/go/pkg/mod/go.uber.org/fx@v1.23.0/app.go:486)",
+		"main.main (/home/kestn/projects/app/cmd/server/main.go:12)",
+		"runtime.main (/usr/local/go/src/runtime/proc.go:272)",
+	}
+	trace := "go.uber.org/dig.(*Container).Invoke\n\t/home/kestn/go/pkg/mod/go.uber.org/dig@v1.18.0/invoke.go:84\n" +
+		"main.main\n\t/home/kestn/projects/app/cmd/server/main.go:12\n" +
+		"runtime.main\n\t/usr/local/go/src/runtime/proc.go:272\n"
+
+	tests := []struct {
+		name           string
+		give           []Option
+		wantStackTrace []any
+		wantStack      string
+	}{
+		{
+			name: "trim paths",
+			give: []Option{WithTrimPaths("/home/kestn/projects/app", "/usr/local/go/src")},
+			wantStackTrace: []any{
+				"go.uber.org/fx.New (go.uber.org/fx@v1.23.0/app.go:486)",
+				"main.main (cmd/server/main.go:12)",
+				"runtime.main (runtime/proc.go:272)",
+			},
+			wantStack: "go.uber.org/dig.(*Container).Invoke\n\tgo.uber.org/dig@v1.18.0/invoke.go:84\n" +
+				"main.main\n\tcmd/server/main.go:12\n" +
+				"runtime.main\n\truntime/proc.go:272\n",
+		},
+		{
+			name: "drop default frames",
+			give: []Option{WithDropFrames()},
+			wantStackTrace: []any{
+				"main.main (/home/kestn/projects/app/cmd/server/main.go:12)",
+			},
+			wantStack: "main.main\n\t/home/kestn/projects/app/cmd/server/main.go:12\n",
+		},
+		{
+			name: "drop frames by pattern",
+			give: []Option{WithDropFrames("go.uber.org/*")},
+			wantStackTrace: []any{
+				"main.main (/home/kestn/projects/app/cmd/server/main.go:12)",
+				"runtime.main (/usr/local/go/src/runtime/proc.go:272)",
+			},
+			wantStack: "main.main\n\t/home/kestn/projects/app/cmd/server/main.go:12\n" +
+				"runtime.main\n\t/usr/local/go/src/runtime/proc.go:272\n",
+		},
+		{
+			name: "max frames",
+			give: []Option{WithMaxFrames(1)},
+			wantStackTrace: []any{
+				"go.uber.org/fx.New (/home/kestn/go/pkg/mod/go.uber.org/fx@v1.23.0/app.go:486)",
+			},
+			wantStack: "go.uber.org/dig.(*Container).Invoke\n\t/home/kestn/go/pkg/mod/go.uber.org/dig@v1.18.0/invoke.go:84\n",
+		},
+		{
+			name: "combined",
+			give: []Option{
+				WithTrimPaths("/home/kestn/projects/app"),
+				WithDropFrames("go.uber.org/fx", "go.uber.org/dig"),
+				WithMaxFrames(1),
+			},
+			wantStackTrace: []any{"main.main (cmd/server/main.go:12)"},
+			wantStack:      "main.main\n\tcmd/server/main.go:12\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+			l := New(core, tt.give...)
+			l.LogEvent(&fxevent.Provided{
+				ConstructorName: "bytes.NewBuffer()",
+				StackTrace:      stackTrace,
+				ModuleTrace:     stackTrace[:2],
+				OutputTypeNames: []string{"*bytes.Buffer"},
+			})
+			l.LogEvent(&fxevent.Invoked{
+				FunctionName: "main.run()",
+				Err:          errors.New("some error"),
+				Trace:        trace,
+			})
+
+			logs := observedLogs.TakeAll()
+			require.Len(t, logs, 2)
+			assert.Equal(t, tt.wantStackTrace, logs[0].Fields()["stacktrace"])
+			assert.Equal(t, tt.wantStack, logs[1].Fields()["stack"])
+		})
+	}
+
+	t.Run("structured", func(t *testing.T) {
+		core, observedLogs := newZerologObservableLogger(zerolog.TraceLevel)
+		l := New(core, WithStructuredStacks(), WithTrimPaths(), WithDropFrames())
+		l.LogEvent(&fxevent.Supplied{
+			TypeName:    "*bytes.Buffer",
+			StackTrace:  stackTrace,
+			ModuleTrace: stackTrace[:2],
+		})
+
+		logs := observedLogs.TakeAll()
+		require.Len(t, logs, 1)
+		want := []any{map[string]any{
+			"function": "main.main",
+			"file":     "/home/kestn/projects/app/cmd/server/main.go",
+			"line":     float64(12),
+		}}
+		assert.Equal(t, want, logs[0].Fields()["stacktrace"])
+		assert.Equal(t, want, logs[0].Fields()["moduletrace"])
+	})
+
+	t.Run("invalid max frames", func(t *testing.T) {
+		core, _ := newZerologObservableLogger(zerolog.TraceLevel)
+		assert.PanicsWithError(t, "fxzerolog: invalid max frames -1", func() {
+			New(core, WithMaxFrames(-1))
+		})
+	})
+}
@@ -0,0 +1,190 @@
+package fxzerolog
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/fx/fxevent"
+)
+
+// WithWatchdog reports the OnStart and OnStop hooks that are still running
+// after interval, logging a "hook still executing" line with the runtime so
+// far every interval until the hook returns. Once a hook has been running for
+// dumpAfter, the stacks of all goroutines are logged once, unless dumpAfter
+// is zero. Lines are logged at the level set by WithSlowLevel, warn by
+// default.
+//
+// The watchdog goroutine is started by the first hook executing and exits
+// once no hook is executing, or on fxevent.Stopped or fxevent.RolledBack.
+func WithWatchdog(interval, dumpAfter time.Duration) Option {
+	return func(l *ZerologLogger) {
+		l.watchdog = &watchdog{interval: interval, dumpAfter: dumpAfter}
+	}
+}
+
+// watchdog tracks the hooks in flight.
+type watchdog struct {
+	interval  time.Duration
+	dumpAfter time.Duration
+
+	mu    sync.Mutex
+	hooks map[hookKey][]*pendingHook
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// hookKey identifies the hooks registered by caller with callee.
+type hookKey struct {
+	stop   bool
+	callee string
+	caller string
+}
+
+// pendingHook is a hook in flight.
+type pendingHook struct {
+	event   fxevent.Event
+	info    eventInfo
+	key     hookKey
+	started time.Time
+	dumped  bool
+}
+
+func (w *watchdog) validate() error {
+	if w.interval <= 0 {
+		return fmt.Errorf("fxzerolog: invalid watchdog interval %v", w.interval)
+	}
+
+	if w.dumpAfter < 0 {
+		return fmt.Errorf("fxzerolog: invalid watchdog dump threshold %v", w.dumpAfter)
+	}
+
+	return nil
+}
+
+// observe tracks the hooks executed and executing by l, starting or stopping
+// the watchdog goroutine as needed.
+func (w *watchdog) observe(l *ZerologLogger, event fxevent.Event, info eventInfo, now time.Time) {
+	var stop, done chan struct{}
+
+	w.mu.Lock()
+	switch e := event.(type) {
+	case *fxevent.OnStartExecuting:
+		w.push(event, info, hookKey{callee: e.FunctionName, caller: e.CallerName}, now)
+	case *fxevent.OnStopExecuting:
+		w.push(event, info, hookKey{stop: true, callee: e.FunctionName, caller: e.CallerName}, now)
+	case *fxevent.OnStartExecuted:
+		w.pop(hookKey{callee: e.FunctionName, caller: e.CallerName})
+	case *fxevent.OnStopExecuted:
+		w.pop(hookKey{stop: true, callee: e.FunctionName, caller: e.CallerName})
+	case *fxevent.Stopped, *fxevent.RolledBack:
+		w.hooks = nil
+	}
+
+	switch {
+	case len(w.hooks) > 0 && w.stop == nil:
+		w.stop, w.done = make(chan struct{}), make(chan struct{})
+		go w.run(l, w.stop, w.done)
+	case len(w.hooks) == 0 && w.stop != nil:
+		stop, done = w.stop, w.done
+		w.stop, w.done = nil, nil
+	}
+	w.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// push tracks a hook started at now. It must be called with w.mu held.
+func (w *watchdog) push(event fxevent.Event, info eventInfo, key hookKey, now time.Time) {
+	if w.hooks == nil {
+		w.hooks = make(map[hookKey][]*pendingHook)
+	}
+
+	w.hooks[key] = append(w.hooks[key], &pendingHook{event: event, info: info, key: key, started: now})
+}
+
+// pop stops tracking the oldest hook with key. It must be called with w.mu
+// held.
+func (w *watchdog) pop(key hookKey) {
+	hooks := w.hooks[key]
+	if len(hooks) <= 1 {
+		delete(w.hooks, key)
+		return
+	}
+
+	w.hooks[key] = hooks[1:]
+}
+
+func (w *watchdog) run(l *ZerologLogger, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			now := l.clock()
+			l.mu.Unlock()
+
+			w.check(l, now)
+		}
+	}
+}
+
+// check logs the hooks running for at least an interval at now.
+func (w *watchdog) check(l *ZerologLogger, now time.Time) {
+	type report struct {
+		hook    *pendingHook
+		runtime time.Duration
+		dump    bool
+	}
+
+	var reports []report
+	w.mu.Lock()
+	for _, hooks := range w.hooks {
+		for _, h := range hooks {
+			elapsed := now.Sub(h.started)
+			if elapsed < w.interval {
+				continue
+			}
+
+			dump := w.dumpAfter > 0 && elapsed >= w.dumpAfter && !h.dumped
+			h.dumped = h.dumped || dump
+			reports = append(reports, report{hook: h, runtime: elapsed, dump: dump})
+		}
+	}
+	w.mu.Unlock()
+
+	f := l.fieldNames()
+	level := l.slowLevel()
+	for _, r := range reports {
+		zEvent := l.newEvent(r.hook.event, r.hook.info, level, false).
+			EmbedObject(l.functionField(f.Callee, r.hook.key.callee)).
+			Str(f.Caller, r.hook.key.caller).
+			EmbedObject(l.runtimeField(r.runtime))
+		if r.dump {
+			zEvent.Str(f.Stack, goroutineStacks())
+		}
+		zEvent.Msg("hook still executing")
+	}
+}
+
+// goroutineStacks returns the stacks of all goroutines.
+func goroutineStacks() string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,159 @@
+package fxzerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxevent"
+)
+
+// syncWriter is a writer safe for concurrent use, whose lines can be read
+// while being written.
+type syncWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *syncWriter) lines(t *testing.T) []map[string]any {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(w.buf.String()), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &fields))
+		lines = append(lines, fields)
+	}
+
+	return lines
+}
+
+func (w *syncWriter) messages(t *testing.T, message string) []map[string]any {
+	var lines []map[string]any
+	for _, line := range w.lines(t) {
+		if line[zerolog.MessageFieldName] == message {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func TestWithWatchdog(t *testing.T) {
+	t.Run("hung hook", func(t *testing.T) {
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+		w := &syncWriter{}
+		l := New(zerolog.New(w), WithWatchdog(5*time.Millisecond, 20*time.Millisecond))
+
+		l.LogEvent(&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		require.Eventually(t, func() bool {
+			for _, line := range w.messages(t, "hook still executing") {
+				if _, ok := line["stack"]; ok {
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+
+		l.LogEvent(&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		l.LogEvent(&fxevent.Stopped{})
+		assert.Nil(t, l.watchdog.stop, "watchdog should be stopped")
+
+		lines := w.messages(t, "hook still executing")
+		require.NotEmpty(t, lines)
+		var dumps int
+		for _, line := range lines {
+			assert.Equal(t, "warn", line[zerolog.LevelFieldName])
+			assert.Equal(t, "hook.onStart", line["callee"])
+			assert.Equal(t, "bytes.NewBuffer", line["caller"])
+			assert.NotEmpty(t, line["runtime"])
+			if stack, ok := line["stack"]; ok {
+				dumps++
+				assert.Contains(t, stack, "goroutine ")
+			}
+		}
+		assert.Equal(t, 1, dumps, "goroutines should be dumped once")
+
+		count := len(w.lines(t))
+		time.Sleep(20 * time.Millisecond)
+		assert.Len(t, w.lines(t), count, "nothing should be logged once stopped")
+	})
+
+	t.Run("tracking", func(t *testing.T) {
+		l := New(zerolog.Nop(), WithWatchdog(time.Hour, 0))
+
+		l.LogEvent(&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		l.LogEvent(&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		l.LogEvent(&fxevent.OnStopExecuting{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		assert.Len(t, l.watchdog.hooks, 2)
+		assert.NotNil(t, l.watchdog.stop, "watchdog should be started")
+
+		l.LogEvent(&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		l.LogEvent(&fxevent.OnStopExecuted{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		assert.Len(t, l.watchdog.hooks, 1)
+
+		l.LogEvent(&fxevent.RolledBack{})
+		assert.Empty(t, l.watchdog.hooks)
+		assert.Nil(t, l.watchdog.stop, "watchdog should be stopped")
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		l := New(zerolog.Nop(), WithWatchdog(time.Hour, 0))
+
+		l.LogEvent(&fxevent.OnStartExecuting{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		done := l.watchdog.done
+		require.NotNil(t, done, "watchdog should be started")
+
+		l.LogEvent(&fxevent.OnStartExecuted{FunctionName: "hook.onStart", CallerName: "bytes.NewBuffer"})
+		assert.Nil(t, l.watchdog.stop, "watchdog should be stopped once the last hook returns")
+		select {
+		case <-done:
+		default:
+			t.Error("watchdog goroutine should have exited")
+		}
+
+		l.LogEvent(&fxevent.OnStopExecuting{FunctionName: "hook.onStop", CallerName: "bytes.NewBuffer"})
+		assert.NotNil(t, l.watchdog.stop, "watchdog should be restarted")
+		l.LogEvent(&fxevent.OnStopExecuted{FunctionName: "hook.onStop", CallerName: "bytes.NewBuffer"})
+		assert.Nil(t, l.watchdog.stop)
+	})
+
+	t.Run("quiet hooks", func(t *testing.T) {
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
+		w := &syncWriter{}
+		l := New(zerolog.New(w), WithWatchdog(time.Hour, 0))
+
+		l.LogEvent(&fxevent.OnStopExecuting{FunctionName: "hook.onStop", CallerName: "bytes.NewBuffer"})
+		l.LogEvent(&fxevent.OnStopExecuted{FunctionName: "hook.onStop", CallerName: "bytes.NewBuffer"})
+		l.LogEvent(&fxevent.Stopped{})
+
+		assert.Empty(t, w.messages(t, "hook still executing"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.PanicsWithError(t, "fxzerolog: invalid watchdog interval 0s", func() {
+			New(zerolog.Nop(), WithWatchdog(0, 0))
+		})
+		assert.PanicsWithError(t, "fxzerolog: invalid watchdog dump threshold -1s", func() {
+			New(zerolog.Nop(), WithWatchdog(time.Second, -time.Second))
+		})
+	})
+}